@@ -0,0 +1,201 @@
+// Package api models the exported API of the discovered packages and
+// compares two versions of it, labeling every change as breaking or
+// compatible under the Go 1 compatibility rules.
+package api
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kolosys/proton/internal/discovery"
+)
+
+// Kinds of symbols
+const (
+	KindFunc            = "func"
+	KindMethod          = "method"
+	KindType            = "type"
+	KindField           = "field"
+	KindInterfaceMethod = "interface method"
+	KindEmbedded        = "embedded interface"
+	KindConst           = "const"
+	KindVar             = "var"
+)
+
+// API is the exported API of a set of packages
+type API struct {
+	Packages []*Package `json:"packages"`
+}
+
+// Package is the exported API of a package
+type Package struct {
+	Name       string    `json:"name"`
+	ImportPath string    `json:"import_path"`
+	Symbols    []*Symbol `json:"symbols"` // Ordered by name
+}
+
+// Symbol is an exported symbol. Members are named after their type, e.g.
+// "Client.Do" for a method or "Options.Timeout" for a field.
+type Symbol struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Signature  string `json:"signature"`            // Form compared between versions, e.g. "func(string) error" or a field's type
+	Value      string `json:"value,omitempty"`      // Value of a constant
+	Sealed     bool   `json:"sealed,omitempty"`     // Interface with unexported methods, which only its own package can implement
+	Deprecated bool   `json:"deprecated,omitempty"` // The symbol has a deprecation note
+}
+
+// New collects the exported API of the packages. Main packages and packages
+// below an internal directory can't be imported by other modules and are
+// left out, as are packages without exported symbols.
+func New(packages []*discovery.PackageInfo) *API {
+	api := &API{}
+	for _, pkg := range packages {
+		if pkg.Name == "main" || isInternal(pkg.ImportPath) {
+			continue
+		}
+
+		p := &Package{Name: pkg.Name, ImportPath: pkg.ImportPath}
+		add := func(symbol *Symbol) {
+			p.Symbols = append(p.Symbols, symbol)
+		}
+
+		for _, fn := range pkg.Functions {
+			if fn.Exported {
+				add(funcSymbol(fn.Name, fn))
+			}
+		}
+		addValues(pkg.Constants, KindConst, add)
+		addValues(pkg.Variables, KindVar, add)
+
+		for _, typ := range pkg.Types {
+			if !typ.Exported {
+				continue
+			}
+			addType(typ, add)
+			for _, fn := range typ.Funcs {
+				if fn.Exported {
+					add(funcSymbol(fn.Name, fn))
+				}
+			}
+			addValues(typ.Consts, KindConst, add)
+			addValues(typ.Vars, KindVar, add)
+		}
+
+		if len(p.Symbols) == 0 {
+			continue
+		}
+		sort.SliceStable(p.Symbols, func(i, j int) bool {
+			return p.Symbols[i].Name < p.Symbols[j].Name
+		})
+		api.Packages = append(api.Packages, p)
+	}
+
+	sort.Slice(api.Packages, func(i, j int) bool {
+		return api.Packages[i].ImportPath < api.Packages[j].ImportPath
+	})
+	return api
+}
+
+// addType adds a type along with its methods, exported fields and, for
+// interfaces, the methods and interfaces it declares
+func addType(typ *discovery.EnhancedType, add func(*Symbol)) {
+	symbol := &Symbol{
+		Name:       typ.Name,
+		Kind:       KindType,
+		Signature:  typeSignature(typ),
+		Deprecated: typ.Deprecation != nil,
+	}
+	add(symbol)
+
+	for _, method := range typ.Methods {
+		if method.Exported {
+			add(&Symbol{
+				Name:       typ.Name + "." + method.Name,
+				Kind:       KindMethod,
+				Signature:  "func (" + method.Recv + ") " + method.Name + method.Signature,
+				Deprecated: method.Deprecation != nil,
+			})
+		}
+	}
+
+	for _, field := range typ.Fields {
+		if field.Exported {
+			add(&Symbol{
+				Name:       typ.Name + "." + field.Name,
+				Kind:       KindField,
+				Signature:  field.Type,
+				Deprecated: field.Deprecation != nil,
+			})
+		}
+	}
+
+	for _, method := range typ.InterfaceMethods {
+		if !method.Exported {
+			symbol.Sealed = true
+			continue
+		}
+		add(&Symbol{
+			Name:       typ.Name + "." + method.Name,
+			Kind:       KindInterfaceMethod,
+			Signature:  "func" + method.Signature,
+			Deprecated: method.Deprecation != nil,
+		})
+	}
+	for _, embedded := range typ.InterfaceEmbeds {
+		add(&Symbol{
+			Name:      typ.Name + "." + embedded,
+			Kind:      KindEmbedded,
+			Signature: embedded,
+		})
+	}
+}
+
+// addValues adds the exported names of const or var declarations
+func addValues(values []*discovery.EnhancedValue, kind string, add func(*Symbol)) {
+	for _, value := range values {
+		for _, entry := range value.Entries {
+			if !entry.Exported {
+				continue
+			}
+			symbol := &Symbol{
+				Name:       entry.Name,
+				Kind:       kind,
+				Signature:  entry.Type,
+				Deprecated: value.Deprecation != nil,
+			}
+			if kind == KindConst {
+				symbol.Value = entry.Value
+			}
+			add(symbol)
+		}
+	}
+}
+
+// funcSymbol creates the symbol of a package-level function
+func funcSymbol(name string, fn *discovery.EnhancedFunc) *Symbol {
+	return &Symbol{
+		Name:       name,
+		Kind:       KindFunc,
+		Signature:  "func" + fn.Signature,
+		Deprecated: fn.Deprecation != nil,
+	}
+}
+
+// typeSignature is the first line of a type's declaration, e.g.
+// "type Options struct" or "type Level int"; fields and methods are compared
+// on their own
+func typeSignature(typ *discovery.EnhancedType) string {
+	first, _, _ := strings.Cut(typ.Declaration, "\n")
+	return strings.TrimSuffix(strings.TrimSpace(first), " {")
+}
+
+// isInternal reports whether an import path has an internal element
+func isInternal(importPath string) bool {
+	for _, element := range strings.Split(importPath, "/") {
+		if element == "internal" {
+			return true
+		}
+	}
+	return false
+}
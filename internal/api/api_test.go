@@ -0,0 +1,77 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/discovery"
+)
+
+// discoverAPI discovers the exported API of a module made of the given files
+func discoverAPI(t *testing.T, files map[string]string) *API {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/shapes\n\ngo 1.24\n"
+	files[".proton/config.yml"] = "repository:\n  name: shapes\n  import_path: example.com/shapes\ncache:\n  enabled: false\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := config.Load("", dir)
+	if err != nil {
+		t.Fatalf("loading configuration: %v", err)
+	}
+	packages, err := discovery.New(cfg, dir).DiscoverPackages()
+	if err != nil {
+		t.Fatalf("discovering packages: %v", err)
+	}
+	return New(packages)
+}
+
+func TestLinesMethodSharingFunctionName(t *testing.T) {
+	api := discoverAPI(t, map[string]string{
+		"shapes.go": `// Package shapes computes areas.
+package shapes
+
+// Shape has an area.
+type Shape interface {
+	Area() float64
+}
+
+// Square is a square.
+type Square struct {
+	Side float64
+}
+
+// Area returns the area of the square.
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+// Area returns the area of a shape.
+func Area(s Shape) float64 { return s.Area() }
+`,
+	})
+
+	lines := make(map[string]bool)
+	for _, line := range api.Lines() {
+		lines[line] = true
+	}
+
+	for _, want := range []string{
+		"pkg example.com/shapes, func Area(Shape) float64",
+		"pkg example.com/shapes, method (Square) Area() float64",
+		"pkg example.com/shapes, type Shape interface, Area() float64",
+	} {
+		if !lines[want] {
+			t.Errorf("missing line %q in\n%v", want, api.Lines())
+		}
+	}
+}
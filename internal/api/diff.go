@@ -0,0 +1,310 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of changes
+const (
+	ChangeAdded      = "added"
+	ChangeRemoved    = "removed"
+	ChangeChanged    = "changed"
+	ChangeDeprecated = "deprecated"
+)
+
+// Semantic version bumps, from the least to the most significant
+const (
+	BumpNone  = "none"
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+)
+
+// KindPackage is the kind of changes to whole packages
+const KindPackage = "package"
+
+// Change is an added, removed, changed or newly deprecated package or symbol
+type Change struct {
+	Package     string `json:"package"`          // Import path of the package
+	Symbol      string `json:"symbol,omitempty"` // Empty for changes to whole packages
+	Kind        string `json:"kind"`             // Kind of the symbol, or KindPackage
+	Change      string `json:"change"`
+	Old         string `json:"old,omitempty"` // Signature before the change
+	New         string `json:"new,omitempty"` // Signature after the change
+	Breaking    bool   `json:"breaking"`
+	Description string `json:"description"` // Why the change is breaking or what changed
+}
+
+// Diff is the comparison of two versions of an API
+type Diff struct {
+	Old     string    `json:"old"` // Revisions compared, set by the caller
+	New     string    `json:"new"`
+	Changes []*Change `json:"changes"` // Ordered by package and symbol
+	Bump    string    `json:"bump"`    // Semantic version bump the changes require
+	Version string    `json:"version,omitempty"`
+}
+
+// Compare compares two versions of an API. Members of added and removed
+// types are not reported on their own.
+func Compare(oldAPI, newAPI *API) *Diff {
+	oldPackages, newPackages := packagesByPath(oldAPI), packagesByPath(newAPI)
+	paths := make(map[string]bool)
+	for path := range oldPackages {
+		paths[path] = true
+	}
+	for path := range newPackages {
+		paths[path] = true
+	}
+
+	diff := &Diff{Changes: []*Change{}}
+	for _, path := range sortedPaths(paths) {
+		oldPkg, newPkg := oldPackages[path], newPackages[path]
+		switch {
+		case oldPkg == nil:
+			diff.Changes = append(diff.Changes, &Change{
+				Package:     path,
+				Kind:        KindPackage,
+				Change:      ChangeAdded,
+				Description: "new package",
+			})
+		case newPkg == nil:
+			diff.Changes = append(diff.Changes, &Change{
+				Package:     path,
+				Kind:        KindPackage,
+				Change:      ChangeRemoved,
+				Breaking:    true,
+				Description: "importers of the package no longer compile",
+			})
+		default:
+			diff.Changes = append(diff.Changes, compareSymbols(oldPkg, newPkg)...)
+		}
+	}
+
+	diff.Bump = bumpFor(diff.Changes)
+	return diff
+}
+
+// Breaking returns the breaking changes
+func (d *Diff) Breaking() []*Change {
+	var changes []*Change
+	for _, change := range d.Changes {
+		if change.Breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Compatible returns the changes that don't break users of the API
+func (d *Diff) Compatible() []*Change {
+	var changes []*Change
+	for _, change := range d.Changes {
+		if !change.Breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// compareSymbols compares the symbols of two versions of a package
+func compareSymbols(oldPkg, newPkg *Package) []*Change {
+	oldSymbols, newSymbols := symbolsByName(oldPkg), symbolsByName(newPkg)
+
+	// Members of added and removed types go with their type
+	skipped := make(map[string]bool)
+	for name, symbol := range oldSymbols {
+		if symbol.Kind == KindType && newSymbols[name] == nil {
+			skipped[name] = true
+		}
+	}
+	for name, symbol := range newSymbols {
+		if symbol.Kind == KindType && oldSymbols[name] == nil {
+			skipped[name] = true
+		}
+	}
+	member := func(name string) bool {
+		typeName, _, ok := strings.Cut(name, ".")
+		return ok && skipped[typeName]
+	}
+
+	var changes []*Change
+	report := func(symbol *Symbol, change string, breaking bool, description string) *Change {
+		c := &Change{
+			Package:     newPkg.ImportPath,
+			Symbol:      symbol.Name,
+			Kind:        symbol.Kind,
+			Change:      change,
+			Breaking:    breaking,
+			Description: description,
+		}
+		changes = append(changes, c)
+		return c
+	}
+
+	for _, symbol := range oldPkg.Symbols {
+		if newSymbols[symbol.Name] != nil || member(symbol.Name) {
+			continue
+		}
+		c := report(symbol, ChangeRemoved, true, removedDescription(symbol))
+		c.Old = symbol.Signature
+	}
+
+	for _, symbol := range newPkg.Symbols {
+		oldSymbol := oldSymbols[symbol.Name]
+		if oldSymbol == nil {
+			if member(symbol.Name) {
+				continue
+			}
+			breaking, description := addedImpact(symbol, newSymbols)
+			c := report(symbol, ChangeAdded, breaking, description)
+			c.New = symbol.Signature
+			continue
+		}
+
+		switch {
+		case oldSymbol.Kind != symbol.Kind:
+			c := report(symbol, ChangeChanged, true, fmt.Sprintf("changed from %s to %s", oldSymbol.Kind, symbol.Kind))
+			c.Old, c.New = oldSymbol.Signature, symbol.Signature
+		case oldSymbol.Signature != symbol.Signature && oldSymbol.Signature != "" && symbol.Signature != "":
+			c := report(symbol, ChangeChanged, true, changedDescription(symbol))
+			c.Old, c.New = oldSymbol.Signature, symbol.Signature
+		case symbol.Sealed && !oldSymbol.Sealed:
+			report(symbol, ChangeChanged, true, "unexported method added, implementations outside the package no longer satisfy the interface")
+		case oldSymbol.Value != symbol.Value:
+			c := report(symbol, ChangeChanged, false, "value changed")
+			c.Old, c.New = oldSymbol.Value, symbol.Value
+		}
+
+		if symbol.Deprecated && !oldSymbol.Deprecated {
+			report(symbol, ChangeDeprecated, false, "deprecated")
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Symbol < changes[j].Symbol
+	})
+	return changes
+}
+
+// addedImpact reports whether adding a symbol breaks users of the API and
+// why. New methods of interfaces break implementations outside the package,
+// unless unexported methods already prevent those.
+func addedImpact(symbol *Symbol, symbols map[string]*Symbol) (bool, string) {
+	if symbol.Kind != KindInterfaceMethod && symbol.Kind != KindEmbedded {
+		return false, "new " + symbol.Kind
+	}
+
+	typeName, _, _ := strings.Cut(symbol.Name, ".")
+	if iface := symbols[typeName]; iface != nil && iface.Sealed {
+		return false, "new " + symbol.Kind + " of an interface only its package can implement"
+	}
+	return true, "implementations outside the package must add the method"
+}
+
+// removedDescription explains why removing a symbol is breaking
+func removedDescription(symbol *Symbol) string {
+	switch symbol.Kind {
+	case KindInterfaceMethod, KindEmbedded:
+		return "callers of the method no longer compile"
+	case KindField:
+		return "code using the field no longer compiles"
+	default:
+		return "code using the " + symbol.Kind + " no longer compiles"
+	}
+}
+
+// changedDescription explains what changed about a symbol's signature
+func changedDescription(symbol *Symbol) string {
+	switch symbol.Kind {
+	case KindFunc, KindMethod, KindInterfaceMethod:
+		return "signature changed"
+	case KindType:
+		return "declaration changed"
+	default:
+		return "type changed"
+	}
+}
+
+// bumpFor determines the semantic version bump changes require: major for
+// breaking changes, minor for additions and deprecations, patch for anything
+// else
+func bumpFor(changes []*Change) string {
+	bump := BumpNone
+	for _, change := range changes {
+		switch {
+		case change.Breaking:
+			return BumpMajor
+		case change.Change == ChangeAdded || change.Change == ChangeDeprecated:
+			bump = BumpMinor
+		case bump == BumpNone:
+			bump = BumpPatch
+		}
+	}
+	return bump
+}
+
+// NextVersion applies a bump to a semantic version such as "v1.4.2" or
+// "tools/v1.4.2", returning "" if version isn't a release version. Major
+// versions 0 make no compatibility promise, so breaking changes bump their
+// minor version.
+func NextVersion(version, bump string) string {
+	slash := strings.LastIndex(version, "/")
+	prefix, version := version[:slash+1], version[slash+1:]
+
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if !strings.HasPrefix(version, "v") || len(parts) != 3 {
+		return ""
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part != strconv.Itoa(n) {
+			return ""
+		}
+		numbers[i] = n
+	}
+	major, minor, patch := numbers[0], numbers[1], numbers[2]
+
+	switch {
+	case bump == BumpMajor && major > 0:
+		major, minor, patch = major+1, 0, 0
+	case bump == BumpMajor || bump == BumpMinor:
+		minor, patch = minor+1, 0
+	case bump == BumpPatch:
+		patch++
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%sv%d.%d.%d", prefix, major, minor, patch)
+}
+
+// packagesByPath indexes the packages of an API by import path
+func packagesByPath(api *API) map[string]*Package {
+	packages := make(map[string]*Package, len(api.Packages))
+	for _, pkg := range api.Packages {
+		packages[pkg.ImportPath] = pkg
+	}
+	return packages
+}
+
+// symbolsByName indexes the symbols of a package by name
+func symbolsByName(pkg *Package) map[string]*Symbol {
+	symbols := make(map[string]*Symbol, len(pkg.Symbols))
+	for _, symbol := range pkg.Symbols {
+		symbols[symbol.Name] = symbol
+	}
+	return symbols
+}
+
+// sortedPaths returns the import paths of a set in sorted order
+func sortedPaths(set map[string]bool) []string {
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// shapesSource is the base version of the package compared by TestCompare
+const shapesSource = `// Package shapes computes areas.
+package shapes
+
+// Shape has an area.
+type Shape interface {
+	Area() float64
+}
+
+// Sealed can only be implemented by this package.
+type Sealed interface {
+	Area() float64
+	seal()
+}
+
+// Square is a square.
+type Square struct {
+	Side  float64
+	Color string
+}
+
+// Scale scales the areas of shapes.
+func Scale(factor float64, shapes ...Shape) float64 { return 0 }
+`
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name    string
+		new     string   // New version of shapesSource
+		changes []string // "symbol change breaking"
+		bump    string
+	}{
+		{
+			name:    "changed signature",
+			new:     replace(shapesSource, "shapes ...Shape", "shapes []Shape"),
+			changes: []string{"Scale changed true"},
+			bump:    BumpMajor,
+		},
+		{
+			name:    "removed field",
+			new:     replace(shapesSource, "\tColor string\n", ""),
+			changes: []string{"Square.Color removed true"},
+			bump:    BumpMajor,
+		},
+		{
+			name:    "added interface method",
+			new:     replace(shapesSource, "type Shape interface {\n", "type Shape interface {\n\tPerimeter() float64\n"),
+			changes: []string{"Shape.Perimeter added true"},
+			bump:    BumpMajor,
+		},
+		{
+			name:    "added method of sealed interface",
+			new:     replace(shapesSource, "type Sealed interface {\n", "type Sealed interface {\n\tPerimeter() float64\n"),
+			changes: []string{"Sealed.Perimeter added false"},
+			bump:    BumpMinor,
+		},
+		{
+			name:    "sealed interface",
+			new:     replace(shapesSource, "type Shape interface {\n", "type Shape interface {\n\tshape()\n"),
+			changes: []string{"Shape changed true"},
+			bump:    BumpMajor,
+		},
+		{
+			name:    "added symbol",
+			new:     shapesSource + "\n// Unit is the unit square.\nvar Unit = Square{Side: 1}\n",
+			changes: []string{"Unit added false"},
+			bump:    BumpMinor,
+		},
+		{
+			name:    "deprecated symbol",
+			new:     replace(shapesSource, "// Scale scales the areas of shapes.\n", "// Scale scales the areas of shapes.\n//\n// Deprecated: scale each shape instead.\n"),
+			changes: []string{"Scale deprecated false"},
+			bump:    BumpMinor,
+		},
+		{
+			name: "documentation only",
+			new:  replace(shapesSource, "// Square is a square.", "// Square is a square with sides of equal length."),
+			bump: BumpNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := Compare(
+				discoverAPI(t, map[string]string{"shapes.go": shapesSource}),
+				discoverAPI(t, map[string]string{"shapes.go": tt.new}),
+			)
+
+			var changes []string
+			for _, change := range diff.Changes {
+				changes = append(changes, fmt.Sprintf("%s %s %t", change.Symbol, change.Change, change.Breaking))
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("changes = %q, want %q", changes, tt.changes)
+			}
+			if diff.Bump != tt.bump {
+				t.Errorf("bump = %s, want %s", diff.Bump, tt.bump)
+			}
+		})
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		version, bump, want string
+	}{
+		{"v1.4.2", BumpMajor, "v2.0.0"},
+		{"v1.4.2", BumpMinor, "v1.5.0"},
+		{"v1.4.2", BumpPatch, "v1.4.3"},
+		{"v1.4.2", BumpNone, ""},
+		{"v0.3.1", BumpMajor, "v0.4.0"},
+		{"tools/v1.4.2", BumpMinor, "tools/v1.5.0"},
+		{"v1.4", BumpMinor, ""},
+		{"v1.04.2", BumpMinor, ""},
+		{"main", BumpMinor, ""},
+	}
+
+	for _, tt := range tests {
+		if got := NextVersion(tt.version, tt.bump); got != tt.want {
+			t.Errorf("NextVersion(%q, %q) = %q, want %q", tt.version, tt.bump, got, tt.want)
+		}
+	}
+}
+
+// replace replaces old in s, which must contain it exactly once
+func replace(s, old, new string) string {
+	if n := strings.Count(s, old); n != 1 {
+		panic(fmt.Sprintf("%q occurs %d times", old, n))
+	}
+	return strings.Replace(s, old, new, 1)
+}
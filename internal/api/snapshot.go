@@ -0,0 +1,235 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Lines returns the API as a snapshot in the format of the api files of the
+// Go distribution: one sorted line per symbol, e.g.
+//
+//	pkg example.com/store, func Open(string) (*DB, error)
+//	pkg example.com/store, method (*DB) Close() error
+//	pkg example.com/store, type Options struct, Timeout time.Duration
+//
+// Interfaces list their methods and embedded interfaces on their own line, so
+// adding a method changes the line. Interfaces with unexported methods don't,
+// only their package can implement them.
+func (a *API) Lines() []string {
+	var lines []string
+	for _, pkg := range a.Packages {
+		symbols := symbolsByName(pkg)
+		members := make(map[string][]string)
+		for _, symbol := range pkg.Symbols {
+			if symbol.Kind == KindInterfaceMethod || symbol.Kind == KindEmbedded {
+				typeName, member, _ := strings.Cut(symbol.Name, ".")
+				members[typeName] = append(members[typeName], member)
+			}
+		}
+
+		for _, symbol := range pkg.Symbols {
+			var line string
+			typeName, member, _ := strings.Cut(symbol.Name, ".")
+			switch symbol.Kind {
+			case KindFunc:
+				line = "func " + symbol.Name + strings.TrimPrefix(symbol.Signature, "func")
+			case KindMethod:
+				line = "method " + strings.TrimPrefix(symbol.Signature, "func ")
+			case KindType:
+				line = symbol.Signature
+				if strings.HasSuffix(line, " interface") {
+					switch {
+					case symbol.Sealed:
+						line += " { unexported methods }"
+					case len(members[typeName]) == 0:
+						line += " {}"
+					default:
+						line += " { " + strings.Join(members[typeName], ", ") + " }"
+					}
+				}
+			case KindField:
+				line = symbols[typeName].Signature + ", " + member + " " + symbol.Signature
+			case KindInterfaceMethod:
+				line = symbols[typeName].Signature + ", " + member + strings.TrimPrefix(symbol.Signature, "func")
+			case KindEmbedded:
+				line = symbols[typeName].Signature + ", embedded " + symbol.Signature
+			case KindConst, KindVar:
+				line = symbol.Kind + " " + symbol.Name
+				if symbol.Signature != "" {
+					line += " " + symbol.Signature
+				}
+				if symbol.Value != "" {
+					line += " = " + symbol.Value
+				}
+			}
+			lines = append(lines, "pkg "+pkg.ImportPath+", "+line)
+		}
+	}
+
+	sort.Strings(lines)
+	return lines
+}
+
+// ReadLines reads a snapshot or allowlist file. Blank lines and lines
+// starting with "#" are skipped.
+func ReadLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return lines, nil
+}
+
+// WriteLines writes a snapshot, creating its directory if needed
+func WriteLines(path string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	var content strings.Builder
+	for _, line := range lines {
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	return os.WriteFile(path, []byte(content.String()), 0644)
+}
+
+// LineChange is a snapshot line whose symbol changed
+type LineChange struct {
+	Old string
+	New string
+}
+
+// CheckResult is the comparison of the current API with a snapshot
+type CheckResult struct {
+	Removed []string      // Snapshot lines whose symbol no longer exists
+	Changed []*LineChange // Snapshot lines whose symbol changed
+	Added   []string      // Lines of symbols missing from the snapshot
+}
+
+// Failed reports whether symbols of the snapshot were removed or changed
+func (r *CheckResult) Failed() bool {
+	return len(r.Removed) > 0 || len(r.Changed) > 0
+}
+
+// Check compares the current API lines with a snapshot. Snapshot lines
+// listed in the allowlist may be removed or changed. A removed line and an
+// added line of the same symbol, e.g. a function whose signature changed,
+// are reported as a change.
+func Check(snapshot, allowlist, current []string) *CheckResult {
+	recorded := make(map[string]bool, len(snapshot))
+	for _, line := range snapshot {
+		recorded[line] = true
+	}
+	present := make(map[string]bool, len(current))
+	for _, line := range current {
+		present[line] = true
+	}
+	allowed := make(map[string]bool, len(allowlist))
+	for _, line := range allowlist {
+		allowed[line] = true
+	}
+
+	// Lines of the current API missing from the snapshot, by symbol
+	added := make(map[string]string)
+	result := &CheckResult{}
+	for _, line := range current {
+		if !recorded[line] {
+			added[lineKey(line)] = line
+			result.Added = append(result.Added, line)
+		}
+	}
+
+	for _, line := range snapshot {
+		if present[line] || allowed[line] {
+			continue
+		}
+		if replacement, ok := added[lineKey(line)]; ok {
+			result.Changed = append(result.Changed, &LineChange{Old: line, New: replacement})
+			continue
+		}
+		result.Removed = append(result.Removed, line)
+	}
+	return result
+}
+
+// lineKey identifies the symbol of a snapshot line, e.g.
+// "pkg p, method DB.Close" for "pkg p, method (*DB) Close() error"
+func lineKey(line string) string {
+	pkg, decl, ok := strings.Cut(line, ", ")
+	if !ok {
+		return line
+	}
+	kind, rest, _ := strings.Cut(decl, " ")
+
+	switch kind {
+	case "method":
+		recv, rest, _ := strings.Cut(rest, ") ")
+		recv = strings.TrimPrefix(strings.TrimPrefix(recv, "("), "*")
+		return pkg + ", method " + recv + "." + identifier(rest)
+	case "type":
+		name := identifier(rest)
+		rest = skipTypeParams(rest[len(name):])
+		for _, prefix := range []string{" struct, ", " interface, "} {
+			if member, ok := strings.CutPrefix(rest, prefix); ok {
+				if embedded, ok := strings.CutPrefix(member, "embedded "); ok {
+					return pkg + ", type " + name + ", embedded " + embedded
+				}
+				return pkg + ", type " + name + ", " + identifier(member)
+			}
+		}
+		return pkg + ", type " + name
+	default:
+		return pkg + ", " + kind + " " + identifier(rest)
+	}
+}
+
+// identifier returns the Go identifier s starts with
+func identifier(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	if end < 0 {
+		return s
+	}
+	return s[:end]
+}
+
+// skipTypeParams removes a leading type parameter list, e.g. "[K comparable, V any]"
+func skipTypeParams(s string) string {
+	if !strings.HasPrefix(s, "[") {
+		return s
+	}
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return s[i+1:]
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,193 @@
+// Package cache implements the persistent, content-addressed store that lets
+// discovery skip packages whose sources haven't changed since the last run.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kolosys/proton/internal/config"
+)
+
+// Store is a directory of JSON entries addressed by their cache key
+type Store struct {
+	dir string
+}
+
+// Stats summarizes the contents of a store
+type Stats struct {
+	Entries int
+	Size    int64 // Total size of all entries in bytes
+}
+
+// New creates a store rooted at dir. The directory is created on first write.
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// gitignore is the content of the .gitignore init writes into the store
+// directory. It also marks the directory as a store for Clean.
+const gitignore = "*\n"
+
+// ForProject returns the store configured for a project
+func ForProject(cfg *config.Config, projectPath string) *Store {
+	return New(filepath.Join(projectPath, cfg.Cache.Directory))
+}
+
+// Dir returns the root directory of the store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Get decodes the entry with the given key into value. It reports false when
+// the entry doesn't exist or can't be decoded, in which case the caller
+// recomputes and stores it again.
+func (s *Store) Get(key string, value interface{}) bool {
+	data, err := os.ReadFile(s.entryPath(key))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, value) == nil
+}
+
+// Put stores value under the given key. Entries are written to a temporary
+// file and renamed into place, so concurrent readers never see partial data.
+func (s *Store) Put(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	path := s.entryPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// Clean removes all entries from the store. Directories that weren't created
+// by the store are left alone, so a misconfigured directory never takes the
+// project with it.
+func (s *Store) Clean() error {
+	if _, err := os.Stat(s.dir); os.IsNotExist(err) {
+		return nil
+	}
+	if data, err := os.ReadFile(filepath.Join(s.dir, ".gitignore")); err != nil || string(data) != gitignore {
+		return fmt.Errorf("refusing to remove %s: not a cache directory created by proton", s.dir)
+	}
+
+	if err := os.RemoveAll(s.dir); err != nil {
+		return fmt.Errorf("failed to remove cache directory %s: %w", s.dir, err)
+	}
+	return nil
+}
+
+// Stats counts the entries of the store and their total size
+func (s *Store) Stats() (*Stats, error) {
+	stats := &Stats{}
+
+	err := filepath.WalkDir(s.dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		stats.Entries++
+		stats.Size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory %s: %w", s.dir, err)
+	}
+
+	return stats, nil
+}
+
+// init creates the store directory along with a .gitignore, so the cache
+// never ends up in version control
+func (s *Store) init() error {
+	path := filepath.Join(s.dir, ".gitignore")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(gitignore), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// entryPath returns the file of an entry. Entries are sharded by the first
+// two characters of their key to keep directories small.
+func (s *Store) entryPath(key string) string {
+	return filepath.Join(s.dir, key[:2], key+".json")
+}
+
+// Hasher accumulates the inputs of a cache key
+type Hasher struct {
+	h hash.Hash
+}
+
+// NewHasher creates an empty key hasher
+func NewHasher() *Hasher {
+	return &Hasher{h: sha256.New()}
+}
+
+// Add adds a named input to the key. Inputs are length-prefixed, so distinct
+// sequences of inputs never produce the same key.
+func (h *Hasher) Add(name string, data []byte) {
+	for _, part := range [][]byte{[]byte(name), data} {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(part)))
+		h.h.Write(size[:])
+		h.h.Write(part)
+	}
+}
+
+// Sum returns the key for the inputs added so far
+func (h *Hasher) Sum() string {
+	return hex.EncodeToString(h.h.Sum(nil))
+}
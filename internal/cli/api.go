@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kolosys/proton/internal/api"
+	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/discovery"
+)
+
+var (
+	snapshotFile  string
+	allowlistFile string
+)
+
+// apiCmd represents the api command
+var apiCmd = &cobra.Command{
+	Use:   "api",
+	Short: "Record the exported API and check changes against it",
+	Long: `Record the exported API in a checked-in snapshot and check changes against it.
+
+The snapshot (api.snapshot, api/next.txt by default) lists one symbol per
+line in the format of the Go distribution's api files, e.g.
+  pkg example.com/store, func Open(string) (*DB, error)
+Main packages and internal packages are left out.
+
+proton api check fails when a symbol of the snapshot was removed or changed,
+unless its line is listed in the allowlist (api.allowlist, api/except.txt by
+default). New symbols don't fail the check, record them with proton api
+snapshot.
+
+Examples:
+  proton api snapshot                # Write api/next.txt for the current directory
+  proton api check                   # Fail if recorded symbols were removed or changed
+  proton api check ./my-project     # Check a specific project`,
+}
+
+// apiSnapshotCmd represents the api snapshot command
+var apiSnapshotCmd = &cobra.Command{
+	Use:   "snapshot [project-path]",
+	Short: "Write the exported API to the snapshot file",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runAPISnapshot,
+}
+
+// apiCheckCmd represents the api check command
+var apiCheckCmd = &cobra.Command{
+	Use:           "check [project-path]",
+	Short:         "Fail if symbols of the snapshot were removed or changed",
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          runAPICheck,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func runAPISnapshot(cmd *cobra.Command, args []string) error {
+	cfg, absPath, err := loadProject(args)
+	if err != nil {
+		return err
+	}
+
+	current, err := projectAPI(cfg, absPath)
+	if err != nil {
+		return err
+	}
+
+	path := projectFile(absPath, snapshotFile, cfg.API.Snapshot)
+	lines := current.Lines()
+	if err := api.WriteLines(path, lines); err != nil {
+		return fmt.Errorf("failed to write API snapshot: %w", err)
+	}
+
+	fmt.Printf("Recorded %d API lines in %s\n", len(lines), path)
+	return nil
+}
+
+func runAPICheck(cmd *cobra.Command, args []string) error {
+	cfg, absPath, err := loadProject(args)
+	if err != nil {
+		return err
+	}
+
+	path := projectFile(absPath, snapshotFile, cfg.API.Snapshot)
+	snapshot, err := api.ReadLines(path)
+	if err != nil {
+		return fmt.Errorf("failed to read API snapshot (create it with proton api snapshot): %w", err)
+	}
+
+	// The allowlist is optional
+	allowlist, err := api.ReadLines(projectFile(absPath, allowlistFile, cfg.API.Allowlist))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read API allowlist: %w", err)
+	}
+
+	current, err := projectAPI(cfg, absPath)
+	if err != nil {
+		return err
+	}
+
+	result := api.Check(snapshot, allowlist, current.Lines())
+	for _, line := range result.Removed {
+		fmt.Printf("removed: %s\n", line)
+	}
+	for _, change := range result.Changed {
+		fmt.Printf("changed: %s\n     to: %s\n", change.Old, change.New)
+	}
+
+	if len(result.Added) > 0 {
+		fmt.Printf("%d new API lines are not in %s yet, record them with proton api snapshot\n", len(result.Added), path)
+	}
+	if result.Failed() {
+		return fmt.Errorf("API check failed: %d symbols removed and %d changed, list intended changes in the allowlist",
+			len(result.Removed), len(result.Changed))
+	}
+
+	fmt.Println("API is compatible with the snapshot")
+	return nil
+}
+
+// loadProject resolves the project path given in args and loads its
+// configuration
+func loadProject(args []string) (*config.Config, string, error) {
+	projectPath := "."
+	if len(args) > 0 {
+		projectPath = args[0]
+	}
+
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid project path: %w", err)
+	}
+
+	cfg, err := config.Load(configPath, absPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, absPath, nil
+}
+
+// projectAPI discovers the exported API of the work tree
+func projectAPI(cfg *config.Config, projectPath string) (*api.API, error) {
+	packages, err := discovery.New(cfg, projectPath).DiscoverPackages()
+	if err != nil {
+		return nil, fmt.Errorf("package discovery failed: %w", err)
+	}
+	return api.New(packages), nil
+}
+
+// projectFile resolves a file given on the command line, or else the
+// configured path relative to the project
+func projectFile(projectPath, flagValue, configured string) string {
+	if flagValue != "" {
+		if path, err := filepath.Abs(flagValue); err == nil {
+			return path
+		}
+		return flagValue
+	}
+	if filepath.IsAbs(configured) {
+		return configured
+	}
+	return filepath.Join(projectPath, configured)
+}
+
+func init() {
+	rootCmd.AddCommand(apiCmd)
+	apiCmd.AddCommand(apiSnapshotCmd)
+	apiCmd.AddCommand(apiCheckCmd)
+
+	// Local flags
+	apiCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "path to configuration file")
+	apiCmd.PersistentFlags().StringVar(&snapshotFile, "file", "", "snapshot file (default: api.snapshot)")
+	apiCheckCmd.Flags().StringVar(&allowlistFile, "allow", "", "allowlist of snapshot lines that may be removed or changed (default: api.allowlist)")
+}
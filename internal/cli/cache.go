@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kolosys/proton/internal/cache"
+	"github.com/kolosys/proton/internal/config"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the discovery cache",
+	Long: `Manage the persistent discovery cache.
+
+Proton caches the parsed model of every package under .proton/cache (see the
+cache section of the configuration). Entries are keyed by the package's file
+contents, the configuration and the Proton version, so stale entries are never
+used, but they are not removed automatically either.
+
+Examples:
+  proton cache stats                 # Show cache size for current directory
+  proton cache clean ./my-project   # Remove all cached entries`,
+}
+
+// cacheCleanCmd represents the cache clean command
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean [project-path]",
+	Short: "Remove all cached discovery results",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runCacheClean,
+}
+
+// cacheStatsCmd represents the cache stats command
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats [project-path]",
+	Short: "Show the number and size of cached discovery results",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runCacheStats,
+}
+
+func runCacheClean(cmd *cobra.Command, args []string) error {
+	store, err := projectCache(args)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Clean(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed discovery cache %s\n", store.Dir())
+	return nil
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	store, err := projectCache(args)
+	if err != nil {
+		return err
+	}
+
+	stats, err := store.Stats()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Cache directory: %s\n", store.Dir())
+	fmt.Printf("Entries:         %d\n", stats.Entries)
+	fmt.Printf("Size:            %s\n", formatBytes(stats.Size))
+	return nil
+}
+
+// projectCache opens the discovery cache of the project given in args
+func projectCache(args []string) (*cache.Store, error) {
+	projectPath := "."
+	if len(args) > 0 {
+		projectPath = args[0]
+	}
+
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project path: %w", err)
+	}
+
+	cfg, err := config.Load(configPath, absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	return cache.ForProject(cfg, absPath), nil
+}
+
+// formatBytes formats a size in bytes for display
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+
+	// Local flags
+	cacheCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "path to configuration file")
+}
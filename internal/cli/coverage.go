@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/coverage"
+	"github.com/kolosys/proton/internal/discovery"
+	"github.com/kolosys/proton/internal/templates"
+)
+
+var (
+	coverageFormat     string
+	coverageMin        float64
+	coveragePackageMin float64
+)
+
+// coverageCmd represents the coverage command
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [project-path]",
+	Short: "Report the share of the exported API with doc comments",
+	Long: `Report the documentation coverage of the discovered packages.
+
+Exported functions, types, methods, struct fields and constants are counted,
+methods and fields of exported types only. The report is broken down by
+package and written as a text table, JSON or Markdown.
+
+The command exits with a nonzero status when the total coverage is below
+coverage.minimum or a package is below coverage.package_minimum, so it can
+gate merges in CI.
+
+Examples:
+  proton coverage                          # Report coverage of the current directory
+  proton coverage ./my-project            # Report coverage of a specific project
+  proton coverage --format json           # Write the report as JSON
+  proton coverage --format markdown       # Write the report as Markdown, e.g. for PR comments
+  proton coverage --min 80 --package-min 50 # Fail below 80% in total or 50% in any package`,
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          runCoverage,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func runCoverage(cmd *cobra.Command, args []string) error {
+	projectPath := "."
+	if len(args) > 0 {
+		projectPath = args[0]
+	}
+
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("invalid project path: %w", err)
+	}
+
+	cfg, err := config.Load(configPath, absPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Override the thresholds with command line flags if provided
+	if cmd.Flags().Changed("min") {
+		cfg.Coverage.Minimum = coverageMin
+	}
+	if cmd.Flags().Changed("package-min") {
+		cfg.Coverage.PackageMinimum = coveragePackageMin
+	}
+
+	packages, err := discovery.New(cfg, absPath).DiscoverPackages()
+	if err != nil {
+		return fmt.Errorf("package discovery failed: %w", err)
+	}
+	report := coverage.New(packages)
+
+	switch coverageFormat {
+	case "text":
+		writeCoverageText(report)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode coverage report: %w", err)
+		}
+	case "markdown":
+		engine, err := templates.New(cfg, absPath)
+		if err != nil {
+			return fmt.Errorf("failed to create template engine: %w", err)
+		}
+		content, err := engine.RenderToString("coverage", &templates.Context{
+			Repository: cfg.Repository,
+			Packages:   packages,
+			Config:     cfg,
+			Metadata:   cfg.Metadata,
+			Coverage:   report,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to render coverage report: %w", err)
+		}
+		fmt.Print(content)
+	default:
+		return fmt.Errorf("unknown format %q, expected text, json or markdown", coverageFormat)
+	}
+
+	return report.Check(cfg.Coverage.Minimum, cfg.Coverage.PackageMinimum)
+}
+
+// writeCoverageText writes the report as a table of packages, followed by
+// the undocumented symbols in verbose mode
+func writeCoverageText(report *coverage.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tFUNCTIONS\tTYPES\tMETHODS\tFIELDS\tCONSTANTS\tTOTAL")
+	for _, pkg := range report.Packages {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%.1f%%\n", pkg.ImportPath,
+			formatCounts(pkg.Functions), formatCounts(pkg.Types), formatCounts(pkg.Methods),
+			formatCounts(pkg.Fields), formatCounts(pkg.Constants), pkg.Total().Percent())
+	}
+	w.Flush()
+
+	total := report.Total()
+	fmt.Printf("\nTotal: %.1f%% (%d of %d exported symbols documented)\n", total.Percent(), total.Documented, total.Total)
+
+	if !verbose {
+		return
+	}
+	for _, pkg := range report.Packages {
+		if len(pkg.Undocumented) == 0 {
+			continue
+		}
+		fmt.Printf("\nUndocumented in %s:\n", pkg.ImportPath)
+		for _, name := range pkg.Undocumented {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+}
+
+// formatCounts formats counts as "documented/total"
+func formatCounts(counts coverage.Counts) string {
+	return fmt.Sprintf("%d/%d", counts.Documented, counts.Total)
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+
+	// Local flags
+	coverageCmd.Flags().StringVarP(&coverageFormat, "format", "f", "text", "output format: text, json or markdown")
+	coverageCmd.Flags().Float64Var(&coverageMin, "min", 0, "minimum total coverage in percent (default: coverage.minimum)")
+	coverageCmd.Flags().Float64Var(&coveragePackageMin, "package-min", 0, "minimum coverage of every package in percent (default: coverage.package_minimum)")
+	coverageCmd.Flags().StringVarP(&configPath, "config", "c", "", "path to configuration file")
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kolosys/proton/internal/api"
+	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/discovery"
+	"github.com/kolosys/proton/internal/gitfs"
+	"github.com/kolosys/proton/internal/templates"
+)
+
+var diffFormat string
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <old-ref> <new-ref> [project-path]",
+	Short: "Compare the exported API of two git revisions",
+	Long: `Compare the exported API of two revisions of the project.
+
+Both revisions are read from the local git object store, without checking
+them out, and discovered with the configuration of the work tree. Added,
+removed and changed functions, methods, types, struct fields, interface
+methods, constants and variables are reported, each labeled breaking or
+compatible, along with the semantic version bump they require. Main packages
+and internal packages are left out, other modules can't import them.
+
+The report is written as Markdown for release notes or as JSON for tooling.
+
+Examples:
+  proton diff v1.2.0 HEAD                  # Changes since the v1.2.0 tag
+  proton diff main feature ./my-project    # Changes of a branch of a specific project
+  proton diff v1.2.0 v1.3.0 --format json  # Write the changes as JSON`,
+	Args:          cobra.RangeArgs(2, 3),
+	RunE:          runDiff,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	oldRef, newRef := args[0], args[1]
+	projectPath := "."
+	if len(args) > 2 {
+		projectPath = args[2]
+	}
+
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("invalid project path: %w", err)
+	}
+
+	cfg, err := config.Load(configPath, absPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	oldAPI, err := revisionAPI(cfg, absPath, oldRef)
+	if err != nil {
+		return err
+	}
+	newAPI, err := revisionAPI(cfg, absPath, newRef)
+	if err != nil {
+		return err
+	}
+
+	diff := api.Compare(oldAPI, newAPI)
+	diff.Old, diff.New = oldRef, newRef
+	diff.Version = api.NextVersion(oldRef, diff.Bump)
+
+	switch diffFormat {
+	case "markdown":
+		engine, err := templates.New(cfg, absPath)
+		if err != nil {
+			return fmt.Errorf("failed to create template engine: %w", err)
+		}
+		content, err := engine.RenderToString("api-diff", &templates.APIDiffContext{
+			Context: &templates.Context{
+				Repository: cfg.Repository,
+				Config:     cfg,
+				Metadata:   cfg.Metadata,
+			},
+			Diff: diff,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to render API changes: %w", err)
+		}
+		fmt.Print(content)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diff); err != nil {
+			return fmt.Errorf("failed to encode API changes: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown format %q, expected markdown or json", diffFormat)
+	}
+
+	return nil
+}
+
+// revisionAPI discovers the exported API of the project at a git revision
+func revisionAPI(cfg *config.Config, projectPath, ref string) (*api.API, error) {
+	fsys, err := gitfs.New(projectPath, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revision %s: %w", ref, err)
+	}
+	defer fsys.Close()
+
+	packages, err := discovery.NewFromFS(cfg, projectPath, fsys).DiscoverPackages()
+	if err != nil {
+		return nil, fmt.Errorf("package discovery of %s failed: %w", ref, err)
+	}
+	return api.New(packages), nil
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	// Local flags
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", "markdown", "output format: markdown or json")
+	diffCmd.Flags().StringVarP(&configPath, "config", "c", "", "path to configuration file")
+}
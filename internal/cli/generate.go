@@ -14,6 +14,9 @@ import (
 var (
 	outputDir   string
 	clean       bool
+	noCache     bool
+	verify      bool
+	versioned   bool
 	configPath  string
 	projectPath string
 )
@@ -30,12 +33,16 @@ This command will:
 - Generate GitBook-compatible documentation
 - Create .gitbook.yml configuration
 - Apply custom templates if configured
+- Document release tags from git when versions are enabled
 
 Examples:
   proton generate                    # Generate docs for current directory
   proton generate ./my-project      # Generate docs for specific project
   proton generate --output docs     # Generate with custom output directory
-  proton generate --clean=false     # Don't clean output directory`,
+  proton generate --clean=false     # Don't clean output directory
+  proton generate --no-cache        # Re-parse every package
+  proton generate --verify-examples # Run examples and record their output
+  proton generate --versions        # Also document release tags from git`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runGenerate,
 }
@@ -68,6 +75,15 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("clean") {
 		cfg.Output.Clean = clean
 	}
+	if noCache {
+		cfg.Cache.Enabled = false
+	}
+	if verify {
+		cfg.Discovery.Examples.Verify = true
+	}
+	if versioned {
+		cfg.Versions.Enabled = true
+	}
 
 	// Create generator
 	gen, err := generator.New(cfg, projectPath)
@@ -91,6 +107,9 @@ func init() {
 	generateCmd.Flags().StringVarP(&outputDir, "output", "o", "", "output directory (default: docs)")
 	generateCmd.Flags().BoolVar(&clean, "clean", true, "clean output directory before generation")
 	generateCmd.Flags().StringVarP(&configPath, "config", "c", "", "path to configuration file")
+	generateCmd.Flags().BoolVar(&noCache, "no-cache", false, "ignore and don't update the discovery cache")
+	generateCmd.Flags().BoolVar(&verify, "verify-examples", false, "run examples and fail when their output doesn't match")
+	generateCmd.Flags().BoolVar(&versioned, "versions", false, "also document the versions selected by versions.* in the versions directory")
 
 	// Bind flags to viper
 	viper.BindPFlag("output.directory", generateCmd.Flags().Lookup("output"))
@@ -97,21 +97,40 @@ func createDefaultConfig(projectPath string) *config.Config {
 				IncludePatterns: []string{"./..."},
 				ExcludePatterns: []string{"./vendor/...", "./test/...", "./.git/...", "**/*_test.go"},
 			},
+			Build: config.Build{
+				Platforms: config.DefaultPlatforms,
+			},
 			APIGeneration: config.APIGeneration{
 				Enabled:           true,
 				IncludeUnexported: false,
+				IndexUnexported:   false,
 				IncludeTests:      false,
 				IncludeExamples:   true,
 			},
 			Examples: config.Examples{
 				Enabled:      true,
 				AutoDiscover: true,
+				Verify:       false,
+				Timeout:      "2m",
 			},
 			Guides: config.Guides{
 				Enabled:             true,
 				IncludeContributing: true,
 				IncludeFAQ:          true,
 			},
+			ConfigReference: config.ConfigReference{
+				Enabled: false,
+				Tag:     "yaml",
+				Title:   "Configuration Reference",
+			},
+			CLIReference: config.CLIReference{
+				Enabled: true,
+				Title:   "CLI Reference",
+			},
+			Operations: config.Operations{
+				Enabled: true,
+				Title:   "Operations Reference",
+			},
 		},
 		GitBook: config.GitBook{
 			Theme: "default",
@@ -130,6 +149,29 @@ func createDefaultConfig(projectPath string) *config.Config {
 			IncludeTOC:             true,
 			MaxDepth:               3,
 		},
+		Links: config.Links{
+			Stdlib:   config.DefaultLinkURL,
+			External: config.DefaultLinkURL,
+		},
+		Cache: config.Cache{
+			Enabled:   true,
+			Directory: ".proton/cache",
+		},
+		Coverage: config.Coverage{
+			Page:  false,
+			Title: "Documentation Coverage",
+		},
+		API: config.API{
+			Snapshot:  "api/next.txt",
+			Allowlist: "api/except.txt",
+		},
+		Versions: config.Versions{
+			Enabled:   false,
+			Latest:    3,
+			Tags:      []string{},
+			Branch:    "main",
+			Directory: "versions",
+		},
 	}
 }
 
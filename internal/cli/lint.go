@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/discovery"
+	"github.com/kolosys/proton/internal/lint"
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint [project-path]",
+	Short: "Check doc comments for problems in the generated documentation",
+	Long: `Check the doc comments of the discovered packages.
+
+Problems are reported as file:line:column diagnostics with a rule ID:
+  missing-doc          exported symbol without a doc comment
+  doc-name             doc comment not starting with the name of its symbol
+  unknown-param        Parameters: section documenting a parameter the function doesn't have
+  undocumented-param   Parameters: section leaving out a parameter of the function
+  return-count         Returns: section listing more or fewer results than the function returns
+  deprecated-format    deprecation note that go doc and Proton don't recognize
+
+A //proton:ignore [rule,...] comment in or after a doc comment suppresses
+diagnostics of that declaration, //proton:ignore-file [rule,...] those of the
+whole file. The command exits with a nonzero status when problems are found.
+
+Examples:
+  proton lint                        # Lint the current directory
+  proton lint ./my-project          # Lint a specific project`,
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          runLint,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	projectPath := "."
+	if len(args) > 0 {
+		projectPath = args[0]
+	}
+
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("invalid project path: %w", err)
+	}
+
+	cfg, err := config.Load(configPath, absPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	packages, err := discovery.New(cfg, absPath).DiscoverPackages()
+	if err != nil {
+		return fmt.Errorf("package discovery failed: %w", err)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Path < packages[j].Path
+	})
+
+	linter := lint.New()
+	problems := 0
+	for _, pkg := range packages {
+		diagnostics, err := linter.LintPackage(pkg.Files)
+		if err != nil {
+			return fmt.Errorf("failed to lint package %s: %w", pkg.ImportPath, err)
+		}
+
+		for _, diagnostic := range diagnostics {
+			if rel, err := filepath.Rel(absPath, diagnostic.Position.Filename); err == nil {
+				diagnostic.Position.Filename = rel
+			}
+			fmt.Println(diagnostic)
+		}
+		problems += len(diagnostics)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("found %d doc comment problems", problems)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	// Local flags
+	lintCmd.Flags().StringVarP(&configPath, "config", "c", "", "path to configuration file")
+}
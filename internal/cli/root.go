@@ -6,6 +6,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/kolosys/proton/internal/version"
 )
 
 var (
@@ -26,7 +28,7 @@ It supports:
 - Configurable templates and output
 - GitBook integration with .gitbook.yml generation
 - GitHub Actions support`,
-	Version: "1.0.0",
+	Version: version.Version,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
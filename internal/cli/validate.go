@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/discovery"
 )
 
 // validateCmd represents the validate command
@@ -76,6 +77,13 @@ func runValidate(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("✅ Package discovery configuration is valid\n")
 
+	// Report which packages each discovery pattern matches
+	if cfg.Discovery.Packages.AutoDiscover {
+		if err := reportPatternMatches(cfg, projectPath); err != nil {
+			return fmt.Errorf("❌ Package pattern matching failed: %w", err)
+		}
+	}
+
 	// Validate templates
 	if err := validateTemplates(&cfg.Templates, projectPath); err != nil {
 		return fmt.Errorf("❌ Template validation failed: %w", err)
@@ -174,6 +182,39 @@ func validateDiscovery(discovery *config.Discovery, projectPath string) error {
 	return nil
 }
 
+func reportPatternMatches(cfg *config.Config, projectPath string) error {
+	matches, err := discovery.New(cfg, projectPath).MatchPatterns()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n📦 Package pattern matches:\n")
+	for _, match := range matches {
+		kind := "include"
+		if match.Exclude {
+			kind = "exclude"
+		}
+
+		if len(match.Packages) == 0 {
+			// Unmatched excludes are harmless, unmatched includes usually indicate a typo
+			if match.Exclude {
+				fmt.Printf("   %s %q matched no packages\n", kind, match.Pattern)
+			} else {
+				fmt.Printf("⚠️  Warning: %s pattern %q matched no packages\n", kind, match.Pattern)
+			}
+			continue
+		}
+
+		fmt.Printf("   %s %q matched %d package(s):\n", kind, match.Pattern, len(match.Packages))
+		for _, pkg := range match.Packages {
+			fmt.Printf("     - %s\n", pkg)
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
+
 func validateTemplates(templates *config.Templates, projectPath string) error {
 	// Validate custom template directory
 	if templates.Directory != "" {
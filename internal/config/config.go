@@ -5,9 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
+
+	"github.com/kolosys/proton/internal/modfile"
 )
 
 // Config represents the complete configuration for Proton
@@ -17,9 +20,14 @@ type Config struct {
 	Output     Output     `yaml:"output" mapstructure:"output"`
 	Discovery  Discovery  `yaml:"discovery" mapstructure:"discovery"`
 	Templates  Templates  `yaml:"templates" mapstructure:"templates"`
+	Links      Links      `yaml:"links" mapstructure:"links"`
 	GitBook    GitBook    `yaml:"gitbook" mapstructure:"gitbook"`
 	Metadata   Metadata   `yaml:"metadata" mapstructure:"metadata"`
 	Generation Generation `yaml:"generation" mapstructure:"generation"`
+	Cache      Cache      `yaml:"cache" mapstructure:"cache"`
+	Coverage   Coverage   `yaml:"coverage" mapstructure:"coverage"`
+	API        API        `yaml:"api" mapstructure:"api"`
+	Versions   Versions   `yaml:"versions" mapstructure:"versions"`
 }
 
 type Repository struct {
@@ -38,10 +46,22 @@ type Output struct {
 }
 
 type Discovery struct {
-	Packages      Packages      `yaml:"packages" mapstructure:"packages"`
-	APIGeneration APIGeneration `yaml:"api_generation" mapstructure:"api_generation"`
-	Examples      Examples      `yaml:"examples" mapstructure:"examples"`
-	Guides        Guides        `yaml:"guides" mapstructure:"guides"`
+	// MaxConcurrency limits how many packages are parsed and type-checked
+	// concurrently, 0 uses all CPUs. Dependencies from outside the project
+	// are still loaded one at a time.
+	MaxConcurrency int           `yaml:"max_concurrency" mapstructure:"max_concurrency"`
+	Packages       Packages      `yaml:"packages" mapstructure:"packages"`
+	Build          Build         `yaml:"build" mapstructure:"build"`
+	APIGeneration  APIGeneration `yaml:"api_generation" mapstructure:"api_generation"`
+	Examples       Examples      `yaml:"examples" mapstructure:"examples"`
+	Guides         Guides        `yaml:"guides" mapstructure:"guides"`
+	// ConfigReference generates a reference page for a configuration struct
+	ConfigReference ConfigReference `yaml:"config_reference" mapstructure:"config_reference"`
+	// CLIReference generates a page per cobra command of the project
+	CLIReference CLIReference `yaml:"cli_reference" mapstructure:"cli_reference"`
+	// Operations generates a page per binary listing its flags and
+	// environment variables
+	Operations Operations `yaml:"operations" mapstructure:"operations"`
 }
 
 type Packages struct {
@@ -57,17 +77,70 @@ type Package struct {
 	Description string `yaml:"description" mapstructure:"description"`
 }
 
+// Build controls how build constraints are evaluated during discovery
+type Build struct {
+	// Platforms lists the GOOS/GOARCH targets to document, e.g. "linux/amd64"
+	Platforms []string `yaml:"platforms" mapstructure:"platforms"`
+	// Tags lists additional build tags to satisfy
+	Tags []string `yaml:"tags" mapstructure:"tags"`
+}
+
 type APIGeneration struct {
 	Enabled           bool `yaml:"enabled" mapstructure:"enabled"`
 	IncludeUnexported bool `yaml:"include_unexported" mapstructure:"include_unexported"`
-	IncludeTests      bool `yaml:"include_tests" mapstructure:"include_tests"`
-	IncludeExamples   bool `yaml:"include_examples" mapstructure:"include_examples"`
+	// IndexUnexported lists unexported symbols in the symbol index, package
+	// overviews and navigation. Without it they only appear on the API
+	// reference pages.
+	IndexUnexported bool `yaml:"index_unexported" mapstructure:"index_unexported"`
+	IncludeTests    bool `yaml:"include_tests" mapstructure:"include_tests"`
+	IncludeExamples bool `yaml:"include_examples" mapstructure:"include_examples"`
+}
+
+// ConfigReference selects the struct documented on the configuration
+// reference page
+type ConfigReference struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Type is the root struct qualified by its import path, e.g.
+	// "github.com/kolosys/proton/internal/config.Config"
+	Type string `yaml:"type" mapstructure:"type"`
+	// Tag is the struct tag naming the keys, e.g. "yaml" or "mapstructure"
+	Tag   string `yaml:"tag" mapstructure:"tag"`
+	Title string `yaml:"title" mapstructure:"title"`
+}
+
+// CLIReference controls the command line reference generated from the cobra
+// commands found in the project's packages
+type CLIReference struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Title   string `yaml:"title" mapstructure:"title"`
+}
+
+// Operations controls the operations reference generated for the main
+// packages of the project
+type Operations struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Title   string `yaml:"title" mapstructure:"title"`
 }
 
 type Examples struct {
 	Enabled      bool     `yaml:"enabled" mapstructure:"enabled"`
 	AutoDiscover bool     `yaml:"auto_discover" mapstructure:"auto_discover"`
 	Directories  []string `yaml:"directories" mapstructure:"directories"`
+	// Verify runs example programs and Example functions with the local Go
+	// toolchain, records their real output and fails generation when an
+	// "// Output:" comment no longer matches
+	Verify  bool   `yaml:"verify" mapstructure:"verify"`
+	Timeout string `yaml:"timeout" mapstructure:"timeout"` // Time limit of a single run, e.g. "2m"
+}
+
+// RunTimeout returns the time limit of a single example run. Timeout is
+// validated when the configuration is loaded.
+func (e Examples) RunTimeout() time.Duration {
+	timeout, err := time.ParseDuration(e.Timeout)
+	if err != nil || timeout <= 0 {
+		return 2 * time.Minute
+	}
+	return timeout
 }
 
 type Guides struct {
@@ -93,6 +166,14 @@ type CustomTemplate struct {
 	File string `yaml:"file" mapstructure:"file"`
 }
 
+// Links configures the URLs of symbols documented outside the project. The
+// placeholders {import_path} and {symbol} are replaced by the import path of
+// the package and the symbol name.
+type Links struct {
+	Stdlib   string `yaml:"stdlib" mapstructure:"stdlib"`
+	External string `yaml:"external" mapstructure:"external"`
+}
+
 type GitBook struct {
 	Title       string           `yaml:"title" mapstructure:"title"`
 	Description string           `yaml:"description" mapstructure:"description"`
@@ -120,6 +201,52 @@ type Generation struct {
 	MaxDepth               int    `yaml:"max_depth" mapstructure:"max_depth"`
 }
 
+// Cache controls the persistent discovery cache
+type Cache struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Directory holds the cached discovery results, relative to the project root
+	Directory string `yaml:"directory" mapstructure:"directory"`
+}
+
+// Coverage sets the documentation coverage thresholds checked by proton
+// coverage and controls the coverage page of the API reference
+type Coverage struct {
+	// Minimum is the percentage of exported symbols of the project that
+	// must be documented, 0 disables the check
+	Minimum float64 `yaml:"minimum" mapstructure:"minimum"`
+	// PackageMinimum is the percentage every package must reach, 0 disables
+	// the check
+	PackageMinimum float64 `yaml:"package_minimum" mapstructure:"package_minimum"`
+	Page           bool    `yaml:"page" mapstructure:"page"`
+	Title          string  `yaml:"title" mapstructure:"title"`
+}
+
+// API locates the files of proton api snapshot and proton api check
+type API struct {
+	// Snapshot is the checked-in list of the exported API, relative to the
+	// project root
+	Snapshot string `yaml:"snapshot" mapstructure:"snapshot"`
+	// Allowlist lists snapshot lines that may be removed or changed,
+	// relative to the project root
+	Allowlist string `yaml:"allowlist" mapstructure:"allowlist"`
+}
+
+// Versions controls the documentation of released versions, read from git
+// tags and generated next to the documentation of the work tree
+type Versions struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Latest is the number of the most recent release tags (vX.Y.Z) to
+	// document
+	Latest int `yaml:"latest" mapstructure:"latest"`
+	// Tags lists further tags to document
+	Tags []string `yaml:"tags" mapstructure:"tags"`
+	// Branch is documented along with the tags, "" documents tags only
+	Branch string `yaml:"branch" mapstructure:"branch"`
+	// Directory holds a directory per version, relative to the output
+	// directory
+	Directory string `yaml:"directory" mapstructure:"directory"`
+}
+
 // Load loads configuration from the specified path or discovers it automatically
 // Parameters:
 // - configPath: The path to the configuration file. If empty, the function will search for a config file in the following locations:
@@ -177,6 +304,12 @@ func Load(configPath, projectPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// DefaultLinkURL points symbols outside the project to pkg.go.dev
+const DefaultLinkURL = "https://pkg.go.dev/{import_path}#{symbol}"
+
+// DefaultPlatforms are the GOOS/GOARCH targets documented when none are configured
+var DefaultPlatforms = []string{"linux/amd64", "darwin/arm64", "windows/amd64"}
+
 // setDefaults sets default values for configuration
 func setDefaults(v *viper.Viper) {
 	// Repository defaults
@@ -188,22 +321,42 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("output.gitbook_config", true)
 
 	// Discovery defaults
+	v.SetDefault("discovery.max_concurrency", 0)
 	v.SetDefault("discovery.packages.auto_discover", true)
 	v.SetDefault("discovery.packages.include_patterns", []string{"./..."})
 	v.SetDefault("discovery.packages.exclude_patterns", []string{"./vendor/...", "./test/..."})
 
+	v.SetDefault("discovery.build.platforms", DefaultPlatforms)
+
 	v.SetDefault("discovery.api_generation.enabled", true)
 	v.SetDefault("discovery.api_generation.include_unexported", false)
+	v.SetDefault("discovery.api_generation.index_unexported", false)
 	v.SetDefault("discovery.api_generation.include_tests", false)
 	v.SetDefault("discovery.api_generation.include_examples", true)
 
 	v.SetDefault("discovery.examples.enabled", true)
 	v.SetDefault("discovery.examples.auto_discover", true)
+	v.SetDefault("discovery.examples.verify", false)
+	v.SetDefault("discovery.examples.timeout", "2m")
 
 	v.SetDefault("discovery.guides.enabled", true)
 	v.SetDefault("discovery.guides.include_contributing", true)
 	v.SetDefault("discovery.guides.include_faq", true)
 
+	v.SetDefault("discovery.config_reference.enabled", false)
+	v.SetDefault("discovery.config_reference.tag", "yaml")
+	v.SetDefault("discovery.config_reference.title", "Configuration Reference")
+
+	v.SetDefault("discovery.cli_reference.enabled", true)
+	v.SetDefault("discovery.cli_reference.title", "CLI Reference")
+
+	v.SetDefault("discovery.operations.enabled", true)
+	v.SetDefault("discovery.operations.title", "Operations Reference")
+
+	// Link defaults
+	v.SetDefault("links.stdlib", DefaultLinkURL)
+	v.SetDefault("links.external", DefaultLinkURL)
+
 	// GitBook defaults
 	v.SetDefault("gitbook.theme", "default")
 	v.SetDefault("gitbook.structure.readme", "README.md")
@@ -218,34 +371,47 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("generation.include_generated_notice", true)
 	v.SetDefault("generation.include_toc", true)
 	v.SetDefault("generation.max_depth", 3)
+
+	// Cache defaults
+	v.SetDefault("cache.enabled", true)
+	v.SetDefault("cache.directory", ".proton/cache")
+
+	// Coverage defaults
+	v.SetDefault("coverage.minimum", 0)
+	v.SetDefault("coverage.package_minimum", 0)
+	v.SetDefault("coverage.page", false)
+	v.SetDefault("coverage.title", "Documentation Coverage")
+
+	// API snapshot defaults
+	v.SetDefault("api.snapshot", "api/next.txt")
+	v.SetDefault("api.allowlist", "api/except.txt")
+
+	// Versioned documentation defaults
+	v.SetDefault("versions.enabled", false)
+	v.SetDefault("versions.latest", 3)
+	v.SetDefault("versions.tags", []string{})
+	v.SetDefault("versions.branch", "main")
+	v.SetDefault("versions.directory", "versions")
 }
 
 // autoDetectRepo attempts to auto-detect repository information
 func autoDetectRepo(cfg *Config, projectPath string) error {
-	// Try to read go.mod for module information
-	goModPath := filepath.Join(projectPath, "go.mod")
-	if data, err := os.ReadFile(goModPath); err == nil {
-		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "module ") {
-				modulePath := strings.TrimSpace(strings.TrimPrefix(line, "module"))
-				if cfg.Repository.ImportPath == "" {
-					cfg.Repository.ImportPath = modulePath
-				}
+	// Try to read go.mod, or the first module of a go.work workspace, for module information
+	if modulePath := detectModulePath(projectPath); modulePath != "" {
+		if cfg.Repository.ImportPath == "" {
+			cfg.Repository.ImportPath = modulePath
+		}
 
-				// Extract repository info from module path
-				if strings.Contains(modulePath, "github.com/") {
-					parts := strings.Split(modulePath, "/")
-					if len(parts) >= 3 {
-						if cfg.Repository.Owner == "" {
-							cfg.Repository.Owner = parts[1]
-						}
-						if cfg.Repository.Name == "" {
-							cfg.Repository.Name = parts[2]
-						}
-					}
+		// Extract repository info from module path
+		if strings.Contains(modulePath, "github.com/") {
+			parts := strings.Split(modulePath, "/")
+			if len(parts) >= 3 {
+				if cfg.Repository.Owner == "" {
+					cfg.Repository.Owner = parts[1]
+				}
+				if cfg.Repository.Name == "" {
+					cfg.Repository.Name = parts[2]
 				}
-				break
 			}
 		}
 	}
@@ -258,6 +424,26 @@ func autoDetectRepo(cfg *Config, projectPath string) error {
 	return nil
 }
 
+// detectModulePath returns the module path of the project root. Workspaces
+// without a root go.mod use the first module listed in go.work.
+func detectModulePath(projectPath string) string {
+	if modulePath, err := modfile.ReadModulePath(projectPath); err == nil && modulePath != "" {
+		return modulePath
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, "go.work"))
+	if err != nil {
+		return ""
+	}
+
+	for _, dir := range modfile.WorkUses(data) {
+		if modulePath, err := modfile.ReadModulePath(filepath.Join(projectPath, dir)); err == nil && modulePath != "" {
+			return modulePath
+		}
+	}
+	return ""
+}
+
 // validateAndSetDefaults validates the configuration and sets computed defaults
 func validateAndSetDefaults(cfg *Config) error {
 	// Set GitBook defaults based on repository info
@@ -273,6 +459,54 @@ func validateAndSetDefaults(cfg *Config) error {
 		return fmt.Errorf("repository name is required")
 	}
 
+	if cfg.Discovery.MaxConcurrency < 0 {
+		return fmt.Errorf("discovery.max_concurrency must not be negative")
+	}
+
+	// Validate build platforms
+	if len(cfg.Discovery.Build.Platforms) == 0 {
+		cfg.Discovery.Build.Platforms = DefaultPlatforms
+	}
+	for _, platform := range cfg.Discovery.Build.Platforms {
+		goos, goarch, ok := strings.Cut(platform, "/")
+		if !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("invalid build platform %q, expected GOOS/GOARCH", platform)
+		}
+	}
+
+	// Validate the example run timeout
+	if cfg.Discovery.Examples.Timeout == "" {
+		cfg.Discovery.Examples.Timeout = "2m"
+	}
+	if timeout, err := time.ParseDuration(cfg.Discovery.Examples.Timeout); err != nil || timeout <= 0 {
+		return fmt.Errorf("invalid discovery.examples.timeout %q, expected a positive duration such as \"2m\"", cfg.Discovery.Examples.Timeout)
+	}
+
+	// Validate the coverage thresholds
+	if cfg.Coverage.Minimum < 0 || cfg.Coverage.Minimum > 100 {
+		return fmt.Errorf("coverage.minimum must be a percentage between 0 and 100")
+	}
+	if cfg.Coverage.PackageMinimum < 0 || cfg.Coverage.PackageMinimum > 100 {
+		return fmt.Errorf("coverage.package_minimum must be a percentage between 0 and 100")
+	}
+
+	// The cache directory is removed by proton cache clean, so it must never
+	// be the project itself or lie outside of it
+	if dir := filepath.ToSlash(filepath.Clean(cfg.Cache.Directory)); cfg.Cache.Directory == "" || filepath.IsAbs(cfg.Cache.Directory) || dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+		return fmt.Errorf("cache.directory must be a subdirectory of the project")
+	}
+
+	// Validate the versioned documentation
+	if cfg.Versions.Latest < 0 {
+		return fmt.Errorf("versions.latest must not be negative")
+	}
+	if cfg.Versions.Directory == "" {
+		cfg.Versions.Directory = "versions"
+	}
+	if dir := filepath.ToSlash(filepath.Clean(cfg.Versions.Directory)); filepath.IsAbs(cfg.Versions.Directory) || dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+		return fmt.Errorf("versions.directory must be a subdirectory of the output directory")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,183 @@
+// Package coverage measures how much of the exported API of the discovered
+// packages is documented.
+package coverage
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/kolosys/proton/internal/discovery"
+)
+
+// Counts are the documented and total number of symbols of a kind
+type Counts struct {
+	Documented int `json:"documented"`
+	Total      int `json:"total"`
+}
+
+// Percent returns the documented share in percent, 100 without symbols
+func (c Counts) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Documented) * 100 / float64(c.Total)
+}
+
+// add counts a symbol
+func (c *Counts) add(documented bool) {
+	c.Total++
+	if documented {
+		c.Documented++
+	}
+}
+
+// plus returns the sum of two counts
+func (c Counts) plus(other Counts) Counts {
+	return Counts{Documented: c.Documented + other.Documented, Total: c.Total + other.Total}
+}
+
+// Package is the documentation coverage of a package by kind of symbol
+type Package struct {
+	Name         string   `json:"name"`
+	ImportPath   string   `json:"import_path"`
+	Slug         string   `json:"slug"` // Identifier of the package's pages
+	Functions    Counts   `json:"functions"`
+	Types        Counts   `json:"types"`
+	Methods      Counts   `json:"methods"`
+	Fields       Counts   `json:"fields"`
+	Constants    Counts   `json:"constants"`
+	Undocumented []string `json:"undocumented"` // Exported symbols without doc comment, e.g. "Client.Do"
+}
+
+// Total returns the counts of all kinds of symbols of the package
+func (p *Package) Total() Counts {
+	return p.Functions.plus(p.Types).plus(p.Methods).plus(p.Fields).plus(p.Constants)
+}
+
+// Report is the documentation coverage of the discovered packages
+type Report struct {
+	Packages []*Package `json:"packages"`
+}
+
+// Total returns the counts of all packages
+func (r *Report) Total() Counts {
+	var total Counts
+	for _, pkg := range r.Packages {
+		total = total.plus(pkg.Total())
+	}
+	return total
+}
+
+// Kinds returns the counts of all packages by kind of symbol, in the order
+// functions, types, methods, fields and constants
+func (r *Report) Kinds() []Kind {
+	kinds := []Kind{{Name: "Functions"}, {Name: "Types"}, {Name: "Methods"}, {Name: "Fields"}, {Name: "Constants"}}
+	for _, pkg := range r.Packages {
+		for i, counts := range []Counts{pkg.Functions, pkg.Types, pkg.Methods, pkg.Fields, pkg.Constants} {
+			kinds[i].Counts = kinds[i].Counts.plus(counts)
+		}
+	}
+	return kinds
+}
+
+// Kind is the coverage of a kind of symbol
+type Kind struct {
+	Name string
+	Counts
+}
+
+// New measures the documentation coverage of the exported functions, types,
+// methods, struct fields and constants of the packages. Methods and fields
+// count for exported types only. Packages without exported symbols are left
+// out.
+func New(packages []*discovery.PackageInfo) *Report {
+	report := &Report{}
+	for _, pkg := range packages {
+		coverage := &Package{Name: pkg.Name, ImportPath: pkg.ImportPath, Slug: pkg.Slug}
+		count := func(counts *Counts, name string, documented bool) {
+			counts.add(documented)
+			if !documented {
+				coverage.Undocumented = append(coverage.Undocumented, name)
+			}
+		}
+		countValues := func(values []*discovery.EnhancedValue) {
+			for _, value := range values {
+				for _, entry := range value.Entries {
+					if entry.Exported {
+						count(&coverage.Constants, entry.Name, hasDoc(value.Doc) || hasDoc(entry.Doc))
+					}
+				}
+			}
+		}
+
+		for _, fn := range pkg.Functions {
+			if token.IsExported(fn.Name) {
+				count(&coverage.Functions, fn.Name, hasDoc(fn.RawDoc) || hasDoc(fn.Doc))
+			}
+		}
+		countValues(pkg.Constants)
+
+		for _, typ := range pkg.Types {
+			if !token.IsExported(typ.Name) {
+				continue
+			}
+			count(&coverage.Types, typ.Name, hasDoc(typ.RawDoc) || hasDoc(typ.Doc))
+
+			for _, fn := range typ.Funcs {
+				if token.IsExported(fn.Name) {
+					count(&coverage.Functions, fn.Name, hasDoc(fn.RawDoc) || hasDoc(fn.Doc))
+				}
+			}
+			for _, method := range typ.Methods {
+				if token.IsExported(method.Name) {
+					count(&coverage.Methods, typ.Name+"."+method.Name, hasDoc(method.RawDoc) || hasDoc(method.Doc))
+				}
+			}
+			for _, field := range typ.Fields {
+				if field.Exported && !field.Embedded {
+					count(&coverage.Fields, typ.Name+"."+field.Name, hasDoc(field.RawDoc) || hasDoc(field.Doc))
+				}
+			}
+			countValues(typ.Consts)
+		}
+
+		if coverage.Total().Total > 0 {
+			sort.Strings(coverage.Undocumented)
+			report.Packages = append(report.Packages, coverage)
+		}
+	}
+
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].ImportPath < report.Packages[j].ImportPath
+	})
+	return report
+}
+
+// Check compares the report with minimum percentages of the whole project
+// and of each package, returning an error listing what falls short. A
+// minimum of 0 disables its check.
+func (r *Report) Check(minimum, packageMinimum float64) error {
+	var failures []string
+	if total := r.Total(); minimum > 0 && total.Percent() < minimum {
+		failures = append(failures, fmt.Sprintf("total coverage %.1f%% is below the minimum of %.1f%%", total.Percent(), minimum))
+	}
+	if packageMinimum > 0 {
+		for _, pkg := range r.Packages {
+			if percent := pkg.Total().Percent(); percent < packageMinimum {
+				failures = append(failures, fmt.Sprintf("coverage of %s %.1f%% is below the package minimum of %.1f%%", pkg.ImportPath, percent, packageMinimum))
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("documentation coverage too low:\n  %s", strings.Join(failures, "\n  "))
+	}
+	return nil
+}
+
+// hasDoc reports whether a doc comment has any text
+func hasDoc(doc string) bool {
+	return strings.TrimSpace(doc) != ""
+}
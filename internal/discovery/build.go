@@ -0,0 +1,257 @@
+package discovery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// platformContext pairs a target platform with the build context used to
+// evaluate build constraints for it
+type platformContext struct {
+	name    string // GOOS/GOARCH, e.g. "linux/amd64"
+	context build.Context
+}
+
+// newPlatformContexts creates a build context for every configured platform
+func newPlatformContexts(platforms, tags []string) []*platformContext {
+	var contexts []*platformContext
+	for _, platform := range platforms {
+		goos, goarch, ok := strings.Cut(platform, "/")
+		if !ok {
+			continue
+		}
+
+		ctx := build.Default
+		ctx.GOOS = goos
+		ctx.GOARCH = goarch
+		ctx.BuildTags = tags
+		// Document cgo files too, they are part of the package on real builds
+		ctx.CgoEnabled = true
+
+		contexts = append(contexts, &platformContext{name: platform, context: ctx})
+	}
+	return contexts
+}
+
+// sourceFile is a Go file selected for a package along with the platforms
+// whose build constraints it satisfies
+type sourceFile struct {
+	path      string
+	platforms []string
+}
+
+// selectPackageFiles evaluates build constraints for every Go file in dir and
+// returns the files of the package documented for that directory. Files that
+// match none of the configured platforms (e.g. "//go:build ignore" tools) are
+// dropped. When several package clauses remain, the package named after the
+// directory wins, then the one with the most files.
+func (d *Discoverer) selectPackageFiles(dir string, includeTests bool) (string, []*sourceFile, error) {
+	entries, err := d.files.ReadDir(dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	byPackage := make(map[string][]*sourceFile)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if strings.HasSuffix(name, "_test.go") && !includeTests {
+			continue
+		}
+
+		var platforms []string
+		for _, platform := range d.platforms {
+			if match, err := platform.context.MatchFile(dir, name); err == nil && match {
+				platforms = append(platforms, platform.name)
+			}
+		}
+		if len(platforms) == 0 {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		clause, err := d.parseFile(path, parser.PackageClauseOnly)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to parse package clause of %s: %w", path, err)
+		}
+
+		// External test packages document nothing on their own
+		pkgName := clause.Name.Name
+		if strings.HasSuffix(pkgName, "_test") {
+			continue
+		}
+
+		byPackage[pkgName] = append(byPackage[pkgName], &sourceFile{path: path, platforms: platforms})
+	}
+
+	if len(byPackage) == 0 {
+		return "", nil, fmt.Errorf("no buildable non-test package found in %s", dir)
+	}
+
+	names := make([]string, 0, len(byPackage))
+	for name := range byPackage {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+		if base := filepath.Base(dir); (a == base) != (b == base) {
+			return a == base
+		}
+		if len(byPackage[a]) != len(byPackage[b]) {
+			return len(byPackage[a]) > len(byPackage[b])
+		}
+		return a < b
+	})
+
+	if len(names) > 1 {
+		fmt.Printf("Warning: %s contains packages %s, documenting %s\n", dir, strings.Join(names, ", "), names[0])
+	}
+
+	return names[0], byPackage[names[0]], nil
+}
+
+// parsedFile is a source file parsed for discovery and type-checking
+type parsedFile struct {
+	once sync.Once
+	file *ast.File
+	err  error
+}
+
+// parseSourceFiles parses the selected files into an AST package. Files are
+// parsed once, whether discovery or the type checker, loading the package as
+// a dependency, needs them first.
+func (d *Discoverer) parseSourceFiles(name string, files []*sourceFile) (*ast.Package, error) {
+	astPkg := &ast.Package{
+		Name:  name,
+		Files: make(map[string]*ast.File),
+	}
+
+	for _, file := range files {
+		d.parsedMu.Lock()
+		parsed, ok := d.parsed[file.path]
+		if !ok {
+			parsed = &parsedFile{}
+			d.parsed[file.path] = parsed
+		}
+		d.parsedMu.Unlock()
+
+		parsed.once.Do(func() {
+			parsed.file, parsed.err = d.parseFile(file.path, parser.ParseComments|parser.AllErrors)
+		})
+		if parsed.err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.path, parsed.err)
+		}
+		astPkg.Files[file.path] = parsed.file
+	}
+
+	return astPkg, nil
+}
+
+// primaryPlatformFiles filters an AST package down to the files built on the
+// first configured platform, so that per-platform duplicates don't conflict
+// during type-checking
+func (d *Discoverer) primaryPlatformFiles(astPkg *ast.Package, files []*sourceFile) map[string]*ast.File {
+	if len(d.platforms) == 0 {
+		return astPkg.Files
+	}
+
+	primary := d.platforms[0].name
+	selected := make(map[string]*ast.File)
+	for _, file := range files {
+		for _, platform := range file.platforms {
+			if platform == primary {
+				selected[file.path] = astPkg.Files[file.path]
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// symbolPlatforms maps each top-level symbol to the platforms of the files
+// declaring it. Methods are keyed as "Type.Method".
+func (d *Discoverer) symbolPlatforms(astPkg *ast.Package, files []*sourceFile) map[string][]string {
+	filePlatforms := make(map[string][]string, len(files))
+	for _, file := range files {
+		filePlatforms[file.path] = file.platforms
+	}
+
+	symbols := make(map[string]map[string]bool)
+	add := func(key string, platforms []string) {
+		if symbols[key] == nil {
+			symbols[key] = make(map[string]bool)
+		}
+		for _, platform := range platforms {
+			symbols[key][platform] = true
+		}
+	}
+
+	for path, file := range astPkg.Files {
+		platforms := filePlatforms[path]
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				key := decl.Name.Name
+				if decl.Recv != nil && len(decl.Recv.List) > 0 {
+					key = typeBaseName(decl.Recv.List[0].Type) + "." + key
+				}
+				add(key, platforms)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						add(spec.Name.Name, platforms)
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							add(name.Name, platforms)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Keep configuration order and drop symbols available everywhere
+	result := make(map[string][]string)
+	for key, set := range symbols {
+		if len(set) == len(d.platforms) {
+			continue
+		}
+		for _, platform := range d.platforms {
+			if set[platform.name] {
+				result[key] = append(result[key], platform.name)
+			}
+		}
+	}
+	return result
+}
+
+// packagePlatforms returns the platforms a package builds on, or nil if it
+// builds on all configured platforms
+func (d *Discoverer) packagePlatforms(files []*sourceFile) []string {
+	set := make(map[string]bool)
+	for _, file := range files {
+		for _, platform := range file.platforms {
+			set[platform] = true
+		}
+	}
+	if len(set) == len(d.platforms) {
+		return nil
+	}
+
+	var platforms []string
+	for _, platform := range d.platforms {
+		if set[platform.name] {
+			platforms = append(platforms, platform.name)
+		}
+	}
+	return platforms
+}
@@ -0,0 +1,199 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kolosys/proton/internal/cache"
+	"github.com/kolosys/proton/internal/version"
+)
+
+// cacheFormat identifies the serialized form of PackageInfo. It is part of
+// every cache key and must be bumped whenever the cached model changes.
+const cacheFormat = "15"
+
+// cachedPackage loads a previously discovered package from the cache. It
+// returns the cache key to store the package under on a miss, or "" when
+// caching is disabled or the key can't be computed.
+func (d *Discoverer) cachedPackage(dir string, files []*sourceFile) (*PackageInfo, string) {
+	if d.cache == nil {
+		return nil, ""
+	}
+
+	key, err := d.cacheKey(dir, files)
+	if err != nil {
+		fmt.Printf("Warning: not caching %s: %v\n", dir, err)
+		return nil, ""
+	}
+
+	var pkgInfo PackageInfo
+	if !d.cache.Get(key, &pkgInfo) {
+		return nil, key
+	}
+	return &pkgInfo, key
+}
+
+// storePackage writes a discovered package to the cache
+func (d *Discoverer) storePackage(key string, pkgInfo *PackageInfo) {
+	if err := d.cache.Put(key, pkgInfo); err != nil {
+		fmt.Printf("Warning: failed to cache %s: %v\n", pkgInfo.ImportPath, err)
+	}
+}
+
+// cacheKey computes the cache key of a package. It covers the selected files
+// and the platforms they build on, the sources of every package of the
+// project it imports (type information flows from those), the go.mod and
+// go.sum of its module, the discovery configuration and the Proton version.
+func (d *Discoverer) cacheKey(dir string, files []*sourceFile) (string, error) {
+	h := cache.NewHasher()
+	h.Add("version", []byte(version.Version))
+	h.Add("format", []byte(cacheFormat))
+	h.Add("config", d.configKey())
+	h.Add("dir", []byte(dir))
+
+	if module := d.moduleFor(dir); module != nil {
+		h.Add("module", []byte(module.Path+"@"+module.Version))
+		for _, name := range []string{"go.mod", "go.sum"} {
+			if data, err := d.files.ReadFile(filepath.Join(module.Dir, name)); err == nil {
+				h.Add(name, data)
+			}
+		}
+	}
+
+	imports := make(map[string]bool)
+	for _, file := range files {
+		data, err := d.files.ReadFile(file.path)
+		if err != nil {
+			return "", err
+		}
+		h.Add("file", []byte(filepath.Base(file.path)+" "+strings.Join(file.platforms, ",")))
+		h.Add("source", data)
+
+		if err := d.collectImports(file.path, data, imports); err != nil {
+			return "", err
+		}
+	}
+
+	for _, path := range sortedKeys(imports) {
+		depDir, ok := d.checker.moduleDirFor(path)
+		if !ok {
+			continue
+		}
+		sum, err := d.sourceHash(depDir, map[string]bool{dir: true})
+		if err != nil {
+			return "", err
+		}
+		h.Add("import "+path, []byte(sum))
+	}
+
+	return h.Sum(), nil
+}
+
+// sourceHash hashes the sources of a package of the project as seen by the
+// type checker, including the packages of the project it imports. Results are
+// memoized for the lifetime of the discoverer.
+func (d *Discoverer) sourceHash(dir string, visiting map[string]bool) (string, error) {
+	d.sourceHashMu.Lock()
+	sum, ok := d.sourceHashes[dir]
+	d.sourceHashMu.Unlock()
+	if ok {
+		return sum, nil
+	}
+
+	h := cache.NewHasher()
+	h.Add("dir", []byte(dir))
+
+	// A directory that doesn't hold an importable package still contributes
+	// its absence, so creating it later changes the key
+	_, files, err := d.selectPackageFiles(dir, false)
+	if err != nil {
+		h.Add("error", []byte(err.Error()))
+	}
+
+	imports := make(map[string]bool)
+	for _, file := range files {
+		if len(d.platforms) > 0 && !containsString(file.platforms, d.platforms[0].name) {
+			continue
+		}
+		data, err := d.files.ReadFile(file.path)
+		if err != nil {
+			return "", err
+		}
+		h.Add("source "+filepath.Base(file.path), data)
+
+		if err := d.collectImports(file.path, data, imports); err != nil {
+			return "", err
+		}
+	}
+
+	visiting[dir] = true
+	defer delete(visiting, dir)
+
+	for _, path := range sortedKeys(imports) {
+		depDir, ok := d.checker.moduleDirFor(path)
+		if !ok || visiting[depDir] {
+			continue
+		}
+		depSum, err := d.sourceHash(depDir, visiting)
+		if err != nil {
+			return "", err
+		}
+		h.Add("import "+path, []byte(depSum))
+	}
+
+	sum = h.Sum()
+	d.sourceHashMu.Lock()
+	d.sourceHashes[dir] = sum
+	d.sourceHashMu.Unlock()
+
+	return sum, nil
+}
+
+// collectImports adds the import paths of a source file to imports
+func (d *Discoverer) collectImports(path string, src []byte, imports map[string]bool) error {
+	file, err := parser.ParseFile(d.fileSet, path, src, parser.ImportsOnly)
+	if err != nil {
+		return fmt.Errorf("failed to parse imports of %s: %w", path, err)
+	}
+
+	for _, spec := range file.Imports {
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			imports[importPath] = true
+		}
+	}
+	return nil
+}
+
+// configKey encodes the configuration that affects discovery results
+func (d *Discoverer) configKey() []byte {
+	data, _ := json.Marshal(struct {
+		ImportPath string
+		Discovery  interface{}
+	}{d.config.Repository.ImportPath, d.config.Discovery})
+	return data
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package discovery
+
+import (
+	"sort"
+	"strings"
+)
+
+// CLIReference is the command tree of the cobra commands found in the
+// discovered packages
+type CLIReference struct {
+	Roots    []*CLICommand
+	Commands []*CLICommand // All commands, depth-first in help order
+}
+
+// CLICommand is a command of the tree with the flags it accepts
+type CLICommand struct {
+	*CobraCommand
+	Name           string       // First word of Use
+	Path           string       // Names from the root command, e.g. "proton cache clean"
+	Depth          int          // Nesting level, 0 for root commands
+	Package        *PackageInfo `json:"-"` // Package defining the command
+	Parent         *CLICommand  `json:"-"`
+	Children       []*CLICommand
+	Flags          []*CobraFlag // Flags registered on the command, including its persistent flags
+	InheritedFlags []*CobraFlag // Persistent flags of the ancestors
+}
+
+// NewCLIReference links the cobra commands of the packages into command
+// trees. Commands are attached to the parents they are added to with
+// AddCommand, across packages, and commands never added to another one are
+// roots. Commands without a constant Use and hidden commands are left out,
+// as cobra leaves hidden commands out of its help.
+func NewCLIReference(packages []*PackageInfo) *CLIReference {
+	commands := make(map[CommandRef]*CLICommand)
+	aliases := make(map[CommandRef]CommandRef)
+	var order []*CLICommand
+	for _, pkg := range packages {
+		if pkg.Cobra == nil {
+			continue
+		}
+		for _, command := range pkg.Cobra.Commands {
+			name, _, _ := strings.Cut(strings.TrimSpace(command.Use), " ")
+			if name == "" || command.Hidden {
+				continue
+			}
+			cliCommand := &CLICommand{CobraCommand: command, Name: name, Package: pkg}
+			commands[command.Ref] = cliCommand
+			order = append(order, cliCommand)
+		}
+		for _, alias := range pkg.Cobra.Aliases {
+			aliases[alias.Parent] = alias.Child
+		}
+	}
+
+	// resolve follows constructors returning commands declared elsewhere
+	resolve := func(ref CommandRef) *CLICommand {
+		for range len(aliases) + 1 {
+			if command, ok := commands[ref]; ok {
+				return command
+			}
+			target, ok := aliases[ref]
+			if !ok {
+				return nil
+			}
+			ref = target
+		}
+		return nil
+	}
+
+	for _, pkg := range packages {
+		if pkg.Cobra == nil {
+			continue
+		}
+		for _, link := range pkg.Cobra.Subcommands {
+			parent, child := resolve(link.Parent), resolve(link.Child)
+			if parent == nil || child == nil || child.Parent != nil || child == parent || isAncestor(child, parent) {
+				continue
+			}
+			child.Parent = parent
+			parent.Children = append(parent.Children, child)
+		}
+
+		for _, flag := range pkg.Cobra.Flags {
+			if command := resolve(flag.Command); command != nil {
+				command.Flags = append(command.Flags, flag)
+			}
+		}
+		for _, required := range pkg.Cobra.Required {
+			if command := resolve(required.Command); command != nil {
+				for _, flag := range command.Flags {
+					if flag.Name == required.Flag {
+						flag.Required = true
+					}
+				}
+			}
+		}
+	}
+
+	reference := &CLIReference{}
+	for _, command := range order {
+		if command.Parent == nil {
+			reference.Roots = append(reference.Roots, command)
+		}
+	}
+	sortCommands(reference.Roots)
+
+	var walk func(command *CLICommand)
+	walk = func(command *CLICommand) {
+		command.Path = command.Name
+		if command.Parent != nil {
+			command.Path = command.Parent.Path + " " + command.Name
+			command.Depth = command.Parent.Depth + 1
+		}
+		command.Flags = append(command.Flags, implicitFlags(command)...)
+		sortFlags(command.Flags)
+		command.InheritedFlags = inheritedFlags(command)
+
+		reference.Commands = append(reference.Commands, command)
+		sortCommands(command.Children)
+		for _, child := range command.Children {
+			walk(child)
+		}
+	}
+	for _, root := range reference.Roots {
+		walk(root)
+	}
+
+	return reference
+}
+
+// isAncestor reports whether ancestor is command or one of its parents
+func isAncestor(ancestor, command *CLICommand) bool {
+	for ; command != nil; command = command.Parent {
+		if command == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// implicitFlags returns the flags cobra adds to a command: --help, and
+// --version for commands with a version
+func implicitFlags(command *CLICommand) []*CobraFlag {
+	flags := []*CobraFlag{{Name: "help", Shorthand: "h", Type: "bool", Usage: "help for " + command.Name}}
+	if command.Version {
+		version := &CobraFlag{Name: "version", Type: "bool", Usage: "version for " + command.Name}
+		if command.lookupFlag("v") == nil {
+			version.Shorthand = "v"
+		}
+		flags = append(flags, version)
+	}
+	return flags
+}
+
+// inheritedFlags returns the persistent flags of the ancestors of a command
+// that it doesn't shadow, nearest ancestor first
+func inheritedFlags(command *CLICommand) []*CobraFlag {
+	seen := make(map[string]bool)
+	for _, flag := range command.Flags {
+		seen[flag.Name] = true
+	}
+
+	var inherited []*CobraFlag
+	for parent := command.Parent; parent != nil; parent = parent.Parent {
+		for _, flag := range parent.Flags {
+			if flag.Persistent && !seen[flag.Name] {
+				seen[flag.Name] = true
+				inherited = append(inherited, flag)
+			}
+		}
+	}
+	sortFlags(inherited)
+	return inherited
+}
+
+// lookupFlag finds a flag of the command or its ancestors by name or
+// shorthand
+func (c *CLICommand) lookupFlag(name string) *CobraFlag {
+	for command := c; command != nil; command = command.Parent {
+		for _, flag := range command.Flags {
+			if (flag.Name == name || flag.Shorthand == name) && (command == c || flag.Persistent) {
+				return flag
+			}
+		}
+	}
+	return nil
+}
+
+// UseLine returns the usage line of the command, e.g.
+// "proton cache clean [flags]"
+func (c *CLICommand) UseLine() string {
+	line := strings.TrimSpace(c.Use)
+	if c.Parent != nil {
+		line = c.Parent.Path + " " + line
+	}
+	if !strings.Contains(line, "[flags]") {
+		line += " [flags]"
+	}
+	return line
+}
+
+// Page returns the file name of the command's page, e.g.
+// "proton_cache_clean.md"
+func (c *CLICommand) Page() string {
+	return strings.ReplaceAll(c.Path, " ", "_") + ".md"
+}
+
+// Flag formats the flag as it is passed, e.g. "-o, --output" or "--no-cache"
+func (f *CobraFlag) Flag() string {
+	if f.Shorthand != "" {
+		return "-" + f.Shorthand + ", --" + f.Name
+	}
+	return "--" + f.Name
+}
+
+// sortCommands orders commands by name, like cobra lists them
+func sortCommands(commands []*CLICommand) {
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+}
+
+// sortFlags orders flags by name, like pflag lists them
+func sortFlags(flags []*CobraFlag) {
+	sort.SliceStable(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+}
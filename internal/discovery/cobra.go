@@ -0,0 +1,538 @@
+package discovery
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// cobraImportPath is the import path of the cobra CLI library
+const cobraImportPath = "github.com/spf13/cobra"
+
+// CobraFacts are the cobra command definitions, AddCommand calls and flag
+// registrations found in a package by static analysis. Commands of several
+// packages are linked into a tree by NewCLIReference.
+type CobraFacts struct {
+	Commands    []*CobraCommand
+	Subcommands []*CobraLink     // AddCommand calls
+	Aliases     []*CobraLink     // Functions returning a command declared elsewhere, Parent is the function
+	Flags       []*CobraFlag     // Flag registrations
+	Required    []*CobraRequired // MarkFlagRequired calls
+}
+
+// CommandRef identifies a command: by its variable, e.g. "rootCmd", by its
+// constructor, e.g. "newServeCmd()", or by the function and local variable
+// declaring it, e.g. "setup.cmd"
+type CommandRef struct {
+	ImportPath string
+	ID         string
+}
+
+// CobraCommand is a cobra.Command literal
+type CobraCommand struct {
+	Ref        CommandRef
+	Use        string
+	Aliases    []string
+	Short      string
+	Long       string
+	Example    string
+	Deprecated string
+	Args       string // Positional argument validator as written, e.g. "cobra.MaximumNArgs(1)"
+	Version    bool   // Version is set, so cobra adds a --version flag
+	Hidden     bool
+	Runnable   bool // Run or RunE is set
+}
+
+// CobraLink connects a parent command to a child command
+type CobraLink struct {
+	Parent CommandRef
+	Child  CommandRef
+}
+
+// CobraFlag is a flag registered on a command through Flags() or
+// PersistentFlags()
+type CobraFlag struct {
+	Command    CommandRef
+	Name       string
+	Shorthand  string
+	Type       string // pflag type name, e.g. "string", "stringSlice" or "count"; empty for custom values
+	Default    string // Default value as Go literal, empty for zero values
+	Usage      string
+	Persistent bool // Inherited by subcommands
+	Required   bool
+}
+
+// CobraRequired marks a flag of a command as required
+type CobraRequired struct {
+	Command CommandRef
+	Flag    string
+}
+
+// cobraAnalyzer collects the cobra facts of a package
+type cobraAnalyzer struct {
+	*evaluator
+	importPath string
+	facts      *CobraFacts
+	anonymous  int // Counts commands declared inline, e.g. in AddCommand calls
+}
+
+// cobraScope resolves local variables of a function to commands and flag sets
+type cobraScope struct {
+	function string                  // Function key, e.g. "newServeCmd" or "app.root"
+	commands map[string]CommandRef   // Local variables holding commands
+	flagSets map[string]cobraFlagSet // Local variables holding Flags() or PersistentFlags()
+	returned string                  // Local variable returned by the function
+}
+
+// cobraFlagSet is the flag set of a command
+type cobraFlagSet struct {
+	command    CommandRef
+	persistent bool
+}
+
+// collectCobraFacts analyzes the files of a package for cobra commands. It
+// returns nil for packages without any.
+func collectCobraFacts(fileSet *token.FileSet, astPkg *ast.Package, importPath string, imports map[string]string, typesPkg *types.Package) *CobraFacts {
+	analyzer := &cobraAnalyzer{
+		evaluator:  &evaluator{fileSet: fileSet, imports: imports, typesPkg: typesPkg},
+		importPath: importPath,
+		facts:      &CobraFacts{},
+	}
+
+	for _, file := range sortedFiles(astPkg.Files) {
+		if strings.HasSuffix(fileSet.Position(file.Pos()).Filename, "_test.go") {
+			continue
+		}
+		cobraName, ok := fileImportName(file, cobraImportPath)
+		if !ok {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				analyzer.packageVars(decl, cobraName)
+			case *ast.FuncDecl:
+				analyzer.function(decl, cobraName)
+			}
+		}
+	}
+
+	facts := analyzer.facts
+	if len(facts.Commands) == 0 && len(facts.Subcommands) == 0 && len(facts.Flags) == 0 {
+		return nil
+	}
+	return facts
+}
+
+// fileImportName returns the name a file imports a package with
+func fileImportName(file *ast.File, importPath string) (string, bool) {
+	for _, spec := range file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err != nil || path != importPath {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name, true
+		}
+		return guessPackageName(importPath), true
+	}
+	return "", false
+}
+
+// packageVars records the commands declared by package-level variables
+func (a *cobraAnalyzer) packageVars(decl *ast.GenDecl, cobraName string) {
+	if decl.Tok != token.VAR {
+		return
+	}
+	for _, spec := range decl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		for i, name := range valueSpec.Names {
+			if i < len(valueSpec.Values) {
+				if literal := commandLiteral(valueSpec.Values[i], cobraName); literal != nil {
+					a.command(literal, CommandRef{ImportPath: a.importPath, ID: name.Name}, nil)
+				}
+			}
+		}
+	}
+}
+
+// function records the commands declared by a function, the commands it
+// wires together and the flags it registers
+func (a *cobraAnalyzer) function(decl *ast.FuncDecl, cobraName string) {
+	if decl.Body == nil {
+		return
+	}
+
+	scope := &cobraScope{
+		function: decl.Name.Name,
+		commands: make(map[string]CommandRef),
+		flagSets: make(map[string]cobraFlagSet),
+	}
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		scope.function = typeBaseName(decl.Recv.List[0].Type) + "." + decl.Name.Name
+	}
+
+	// The returned command is identified by the function
+	var returns []ast.Expr
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if ret, ok := n.(*ast.ReturnStmt); ok && len(ret.Results) > 0 {
+			returns = append(returns, ret.Results[0])
+		}
+		return true
+	})
+	for _, result := range returns {
+		if ident, ok := unparen(result).(*ast.Ident); ok {
+			scope.returned = ident.Name
+		}
+	}
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			a.assignment(n.Lhs, n.Rhs, scope, cobraName)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			a.assignment(lhs, n.Values, scope, cobraName)
+		case *ast.CallExpr:
+			a.call(n, scope, cobraName)
+		}
+		return true
+	})
+
+	for _, result := range returns {
+		switch result := unparen(result).(type) {
+		case *ast.Ident:
+			if result.Name == scope.returned {
+				if ref, ok := a.resolve(result, scope, cobraName); ok && ref.ID != scope.function+"()" {
+					a.facts.Aliases = append(a.facts.Aliases, &CobraLink{
+						Parent: CommandRef{ImportPath: a.importPath, ID: scope.function + "()"},
+						Child:  ref,
+					})
+				}
+			}
+		default:
+			if literal := commandLiteral(result, cobraName); literal != nil {
+				a.command(literal, CommandRef{ImportPath: a.importPath, ID: scope.function + "()"}, scope)
+			} else if ref, ok := a.resolve(result, scope, cobraName); ok {
+				a.facts.Aliases = append(a.facts.Aliases, &CobraLink{
+					Parent: CommandRef{ImportPath: a.importPath, ID: scope.function + "()"},
+					Child:  ref,
+				})
+			}
+		}
+	}
+}
+
+// assignment records local variables holding commands or flag sets
+func (a *cobraAnalyzer) assignment(lhs, rhs []ast.Expr, scope *cobraScope, cobraName string) {
+	if len(lhs) != len(rhs) {
+		return
+	}
+	for i, target := range lhs {
+		ident, ok := target.(*ast.Ident)
+		if !ok || ident.Name == "_" {
+			continue
+		}
+
+		if literal := commandLiteral(rhs[i], cobraName); literal != nil {
+			id := scope.function + "." + ident.Name
+			if ident.Name == scope.returned {
+				id = scope.function + "()"
+			}
+			ref := CommandRef{ImportPath: a.importPath, ID: id}
+			scope.commands[ident.Name] = ref
+			a.command(literal, ref, scope)
+			continue
+		}
+
+		if flagSet, ok := a.flagSet(rhs[i], scope, cobraName); ok {
+			scope.flagSets[ident.Name] = flagSet
+		}
+	}
+}
+
+// call records AddCommand calls, flag registrations and required flags
+func (a *cobraAnalyzer) call(call *ast.CallExpr, scope *cobraScope, cobraName string) {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	method := selector.Sel.Name
+
+	switch method {
+	case "AddCommand":
+		parent, ok := a.resolve(selector.X, scope, cobraName)
+		if !ok {
+			return
+		}
+		for _, arg := range call.Args {
+			child, ok := a.resolve(arg, scope, cobraName)
+			if literal := commandLiteral(arg, cobraName); literal != nil {
+				a.anonymous++
+				child, ok = CommandRef{ImportPath: a.importPath, ID: scope.function + "#" + strconv.Itoa(a.anonymous)}, true
+				a.command(literal, child, scope)
+			}
+			if ok {
+				a.facts.Subcommands = append(a.facts.Subcommands, &CobraLink{Parent: parent, Child: child})
+			}
+		}
+		return
+
+	case "MarkFlagRequired", "MarkPersistentFlagRequired":
+		command, ok := a.resolve(selector.X, scope, cobraName)
+		if !ok || len(call.Args) == 0 {
+			return
+		}
+		if name, ok := a.stringValue(call.Args[0]); ok {
+			a.facts.Required = append(a.facts.Required, &CobraRequired{Command: command, Flag: name})
+		}
+		return
+	}
+
+	flagSet, ok := a.flagSet(selector.X, scope, cobraName)
+	if !ok {
+		return
+	}
+	if flag := a.flag(method, call.Args); flag != nil {
+		flag.Command, flag.Persistent = flagSet.command, flagSet.persistent
+		a.facts.Flags = append(a.facts.Flags, flag)
+	}
+}
+
+// flagSet resolves cmd.Flags(), cmd.PersistentFlags() or a local variable
+// holding one of them
+func (a *cobraAnalyzer) flagSet(expr ast.Expr, scope *cobraScope, cobraName string) (cobraFlagSet, bool) {
+	expr = unparen(expr)
+	if ident, ok := expr.(*ast.Ident); ok {
+		flagSet, ok := scope.flagSets[ident.Name]
+		return flagSet, ok
+	}
+
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return cobraFlagSet{}, false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return cobraFlagSet{}, false
+	}
+
+	var persistent bool
+	switch selector.Sel.Name {
+	case "Flags", "LocalFlags":
+	case "PersistentFlags":
+		persistent = true
+	default:
+		return cobraFlagSet{}, false
+	}
+
+	command, ok := a.resolve(selector.X, scope, cobraName)
+	if !ok {
+		return cobraFlagSet{}, false
+	}
+	return cobraFlagSet{command: command, persistent: persistent}, true
+}
+
+// resolve identifies the command an expression refers to. References to
+// variables of other packages or unknown variables resolve as well, they are
+// linked or dropped by NewCLIReference.
+func (a *cobraAnalyzer) resolve(expr ast.Expr, scope *cobraScope, cobraName string) (CommandRef, bool) {
+	switch expr := unparen(expr).(type) {
+	case *ast.UnaryExpr:
+		if expr.Op == token.AND {
+			return a.resolve(expr.X, scope, cobraName)
+		}
+	case *ast.Ident:
+		if scope != nil {
+			if ref, ok := scope.commands[expr.Name]; ok {
+				return ref, true
+			}
+			if expr.Name == scope.returned {
+				return CommandRef{ImportPath: a.importPath, ID: scope.function + "()"}, true
+			}
+		}
+		return CommandRef{ImportPath: a.importPath, ID: expr.Name}, true
+	case *ast.SelectorExpr:
+		if pkg, ok := expr.X.(*ast.Ident); ok && pkg.Name != cobraName {
+			if importPath, ok := a.imports[pkg.Name]; ok {
+				return CommandRef{ImportPath: importPath, ID: expr.Sel.Name}, true
+			}
+		}
+	case *ast.CallExpr:
+		switch fun := unparen(expr.Fun).(type) {
+		case *ast.Ident:
+			return CommandRef{ImportPath: a.importPath, ID: fun.Name + "()"}, true
+		case *ast.SelectorExpr:
+			if pkg, ok := fun.X.(*ast.Ident); ok {
+				if importPath, ok := a.imports[pkg.Name]; ok && pkg.Name != cobraName {
+					return CommandRef{ImportPath: importPath, ID: fun.Sel.Name + "()"}, true
+				}
+			}
+		}
+	}
+	return CommandRef{}, false
+}
+
+// command records a cobra.Command literal
+func (a *cobraAnalyzer) command(literal *ast.CompositeLit, ref CommandRef, scope *cobraScope) {
+	command := &CobraCommand{Ref: ref}
+	for _, elt := range literal.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		text, _ := a.stringValue(kv.Value)
+		switch key.Name {
+		case "Use":
+			command.Use = text
+		case "Short":
+			command.Short = text
+		case "Long":
+			command.Long = text
+		case "Example":
+			command.Example = text
+		case "Deprecated":
+			command.Deprecated = text
+		case "Version":
+			command.Version = true
+		case "Hidden":
+			command.Hidden = a.exprString(kv.Value) == "true"
+		case "Run", "RunE":
+			command.Runnable = true
+		case "Args":
+			command.Args = a.exprString(kv.Value)
+		case "Aliases":
+			if list, ok := kv.Value.(*ast.CompositeLit); ok {
+				for _, alias := range list.Elts {
+					if name, ok := a.stringValue(alias); ok {
+						command.Aliases = append(command.Aliases, name)
+					}
+				}
+			}
+		}
+	}
+	a.facts.Commands = append(a.facts.Commands, command)
+}
+
+// flag parses a flag registration such as StringVarP(&p, "name", "n", "", "usage")
+// or Bool("name", false, "usage"), returning nil for other methods
+func (a *cobraAnalyzer) flag(method string, args []ast.Expr) *CobraFlag {
+	base := method
+	hasVar := false
+	hasShorthand := false
+	if base == "Var" || base == "VarP" {
+		hasVar, hasShorthand = true, base == "VarP"
+		base = ""
+	} else {
+		if trimmed, ok := strings.CutSuffix(base, "P"); ok && trimmed != "" {
+			base, hasShorthand = trimmed, true
+		}
+		if trimmed, ok := strings.CutSuffix(base, "Var"); ok && trimmed != "" {
+			base, hasVar = trimmed, true
+		}
+		if !pflagTypes[base] {
+			return nil
+		}
+	}
+
+	// Count flags and custom values have no default argument
+	hasDefault := base != "" && base != "Count"
+	want := 2
+	for _, present := range []bool{hasVar, hasShorthand, hasDefault} {
+		if present {
+			want++
+		}
+	}
+	if len(args) != want {
+		return nil
+	}
+
+	i := 0
+	if hasVar {
+		i++
+	}
+	name, ok := a.stringValue(args[i])
+	if !ok {
+		return nil
+	}
+	i++
+
+	flag := &CobraFlag{Name: name, Type: pflagTypeName(base)}
+	if hasShorthand {
+		flag.Shorthand, _ = a.stringValue(args[i])
+		i++
+	}
+	if hasDefault {
+		flag.Default = a.defaultValue(args[i])
+		i++
+	}
+	if usage, ok := a.stringValue(args[i]); ok {
+		flag.Usage = usage
+	} else {
+		flag.Usage = a.exprString(args[i])
+	}
+
+	return flag
+}
+
+// pflagTypes are the flag types registered by methods of pflag.FlagSet,
+// e.g. String, StringP, StringVar and StringVarP
+var pflagTypes = map[string]bool{
+	"Bool": true, "BoolSlice": true, "BytesBase64": true, "BytesHex": true, "Count": true,
+	"Duration": true, "DurationSlice": true, "Float32": true, "Float32Slice": true,
+	"Float64": true, "Float64Slice": true, "IP": true, "IPMask": true, "IPNet": true,
+	"IPSlice": true, "Int": true, "Int16": true, "Int32": true, "Int32Slice": true,
+	"Int64": true, "Int64Slice": true, "Int8": true, "IntSlice": true, "String": true,
+	"StringArray": true, "StringSlice": true, "StringToInt": true, "StringToInt64": true,
+	"StringToString": true, "Uint": true, "Uint16": true, "Uint32": true, "Uint64": true,
+	"Uint8": true, "UintSlice": true,
+}
+
+// pflagTypeName returns the type name pflag reports for a registration
+// method's base name, e.g. "stringSlice" for StringSlice
+func pflagTypeName(base string) string {
+	if base == "" {
+		return ""
+	}
+	if rest, ok := strings.CutPrefix(base, "IP"); ok {
+		return "ip" + rest
+	}
+	return strings.ToLower(base[:1]) + base[1:]
+}
+
+// commandLiteral returns the composite literal of &cobra.Command{...} or
+// cobra.Command{...}, or nil for other expressions
+func commandLiteral(expr ast.Expr, cobraName string) *ast.CompositeLit {
+	expr = unparen(expr)
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	literal, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	selector, ok := literal.Type.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Command" {
+		return nil
+	}
+	if pkg, ok := selector.X.(*ast.Ident); !ok || pkg.Name != cobraName {
+		return nil
+	}
+	return literal
+}
@@ -0,0 +1,278 @@
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// ConfigReference documents the keys of a configuration struct, e.g. the
+// keys of a YAML file decoded into it
+type ConfigReference struct {
+	Package  *PackageInfo // Package declaring the root struct
+	Type     string       // Name of the root struct, e.g. "Config"
+	Tag      string       // Struct tag naming the keys, e.g. "yaml"
+	Sections []*ConfigSection
+}
+
+// ConfigSection groups the keys below a top-level struct key. Scalar keys of
+// the root struct are collected in a section without Key.
+type ConfigSection struct {
+	Key  *ConfigKey
+	Keys []*ConfigKey
+}
+
+// ConfigKey is a single configuration key
+type ConfigKey struct {
+	Key       string       // Full dotted key, e.g. "discovery.build.platforms"; "[]" marks slice elements
+	Depth     int          // Nesting level, 0 for keys of the root struct
+	Type      string       // Go type of the field
+	Package   *PackageInfo // Package declaring the field, for resolving links in Type and Doc
+	Doc       string       // Doc comment of the field in go/doc/comment syntax
+	Default   string       // Default value registered with SetDefault, empty if none was found
+	OmitEmpty bool
+	Required  bool
+	Struct    bool // The key holds nested keys
+	*Deprecation
+}
+
+// NewConfigReference walks the struct root, given as "import/path.Type", and
+// lists its keys as named by tag. Nested structs of discovered packages are
+// walked recursively, defaults are looked up in the SetDefault calls of all
+// discovered packages.
+func NewConfigReference(index *SymbolIndex, root, tag string) (*ConfigReference, error) {
+	dot := strings.LastIndex(root, ".")
+	if dot < 0 {
+		return nil, fmt.Errorf("invalid configuration type %q, expected import/path.Type", root)
+	}
+	importPath, typeName := root[:dot], root[dot+1:]
+
+	pkg := index.Package(importPath)
+	if pkg == nil {
+		return nil, fmt.Errorf("package %s of configuration type %s was not discovered", importPath, typeName)
+	}
+	typ := findType(pkg, typeName)
+	if typ == nil || typ.TypeKind != "struct" {
+		return nil, fmt.Errorf("configuration type %s is not a documented struct", root)
+	}
+
+	// Defaults registered by the package of the root struct take precedence
+	defaults := make(map[string]string)
+	for _, discovered := range index.packages {
+		if discovered != pkg {
+			for key, value := range discovered.Defaults {
+				defaults[key] = value
+			}
+		}
+	}
+	for key, value := range pkg.Defaults {
+		defaults[key] = value
+	}
+
+	ref := &ConfigReference{Package: pkg, Type: typeName, Tag: tag}
+	walker := &configWalker{index: index, tag: tag, defaults: defaults, visiting: make(map[*EnhancedType]bool)}
+	keys := walker.walk(pkg, typ, "", 0)
+
+	// Top-level structs open a section, other keys go first
+	scalars := &ConfigSection{}
+	for _, key := range keys {
+		switch {
+		case key.Depth == 0 && key.Struct:
+			ref.Sections = append(ref.Sections, &ConfigSection{Key: key})
+		case key.Depth == 0 || len(ref.Sections) == 0:
+			scalars.Keys = append(scalars.Keys, key)
+		default:
+			section := ref.Sections[len(ref.Sections)-1]
+			section.Keys = append(section.Keys, key)
+		}
+	}
+	if len(scalars.Keys) > 0 {
+		ref.Sections = append([]*ConfigSection{scalars}, ref.Sections...)
+	}
+
+	return ref, nil
+}
+
+// configWalker collects the keys of nested configuration structs
+type configWalker struct {
+	index    *SymbolIndex
+	tag      string
+	defaults map[string]string
+	visiting map[*EnhancedType]bool // Guards against recursive types
+}
+
+// walk lists the keys of the struct typ of pkg below prefix
+func (w *configWalker) walk(pkg *PackageInfo, typ *EnhancedType, prefix string, depth int) []*ConfigKey {
+	if w.visiting[typ] {
+		return nil
+	}
+	w.visiting[typ] = true
+	defer delete(w.visiting, typ)
+
+	var keys []*ConfigKey
+	for _, field := range typ.Fields {
+		if !field.Exported {
+			continue
+		}
+
+		name := field.Name
+		if w.tag == "yaml" {
+			name = strings.ToLower(field.Name)
+		}
+		var structTag *StructTag
+		if structTag = field.LookupTag(w.tag); structTag != nil {
+			if structTag.Name == "-" {
+				continue
+			}
+			if structTag.Name != "" {
+				name = structTag.Name
+			}
+		}
+
+		nestedPkg, nested, elem := w.resolveStruct(pkg, field.Type)
+
+		// Inlined structs contribute their keys at the same level
+		if field.Embedded && structTag != nil && (structTag.HasOption("inline") || structTag.HasOption("squash")) {
+			if nested != nil {
+				keys = append(keys, w.walk(nestedPkg, nested, prefix, depth)...)
+			}
+			continue
+		}
+
+		key := &ConfigKey{
+			Key:         prefix + name,
+			Depth:       depth,
+			Type:        field.Type,
+			Package:     pkg,
+			Doc:         field.RawDoc,
+			Default:     w.defaults[strings.ToLower(prefix+name)],
+			Required:    fieldRequired(field),
+			Struct:      nested != nil,
+			Deprecation: field.Deprecation,
+		}
+		if structTag != nil {
+			key.OmitEmpty = structTag.HasOption("omitempty")
+		}
+		keys = append(keys, key)
+
+		if nested != nil {
+			keys = append(keys, w.walk(nestedPkg, nested, key.Key+elem+".", depth+1)...)
+		}
+	}
+
+	return keys
+}
+
+// resolveStruct resolves a field type to a documented struct of a discovered
+// package. Pointers are followed, slices and maps of structs are walked with
+// elem appended to their key, "[]" for slices and ".*" for maps.
+func (w *configWalker) resolveStruct(pkg *PackageInfo, typeExpr string) (*PackageInfo, *EnhancedType, string) {
+	elem := ""
+	for {
+		switch {
+		case strings.HasPrefix(typeExpr, "*"):
+			typeExpr = typeExpr[1:]
+			continue
+		case strings.HasPrefix(typeExpr, "[]"):
+			typeExpr, elem = typeExpr[2:], elem+"[]"
+			continue
+		case strings.HasPrefix(typeExpr, "map["):
+			if end := strings.Index(typeExpr, "]"); end > 0 {
+				typeExpr, elem = typeExpr[end+1:], elem+".*"
+				continue
+			}
+		}
+		break
+	}
+
+	target := pkg
+	if qualifier, name, ok := strings.Cut(typeExpr, "."); ok {
+		target = w.index.Package(pkg.Imports[qualifier])
+		typeExpr = name
+	}
+	if target == nil {
+		return nil, nil, ""
+	}
+
+	typ := findType(target, typeExpr)
+	if typ == nil || typ.TypeKind != "struct" {
+		return nil, nil, ""
+	}
+	return target, typ, elem
+}
+
+// fieldRequired reports whether a field is marked required by a validation
+// tag, e.g. `validate:"required"`, `binding:"required"` or `required:"true"`
+func fieldRequired(field *Field) bool {
+	for _, key := range []string{"validate", "binding"} {
+		if tag := field.LookupTag(key); tag != nil && (tag.Name == "required" || tag.HasOption("required")) {
+			return true
+		}
+	}
+	if tag := field.LookupTag("required"); tag != nil && tag.Name == "true" {
+		return true
+	}
+	return false
+}
+
+// findType returns the documented type of a package with the given name
+func findType(pkg *PackageInfo, name string) *EnhancedType {
+	for _, typ := range pkg.Types {
+		if typ.Name == name {
+			return typ
+		}
+	}
+	return nil
+}
+
+// collectDefaults finds the default values registered by SetDefault calls,
+// e.g. viper's v.SetDefault("output.clean", true), keyed by the lowercased
+// key. Constants are resolved to their values, other expressions are kept as
+// written.
+func collectDefaults(fileSet *token.FileSet, astPkg *ast.Package, typesPkg *types.Package) map[string]string {
+	defaults := make(map[string]string)
+	for _, file := range sortedFiles(astPkg.Files) {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || selector.Sel.Name != "SetDefault" {
+				return true
+			}
+			literal, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || literal.Kind != token.STRING {
+				return true
+			}
+			key, err := strconv.Unquote(literal.Value)
+			if err != nil {
+				return true
+			}
+
+			defaults[strings.ToLower(key)] = defaultValue(fileSet, call.Args[1], typesPkg)
+			return true
+		})
+	}
+	return defaults
+}
+
+// defaultValue formats the value passed to SetDefault
+func defaultValue(fileSet *token.FileSet, expr ast.Expr, typesPkg *types.Package) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		if constant, ok := lookupObject(typesPkg, ident.Name).(*types.Const); ok {
+			return constant.Val().String()
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fileSet, expr); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
@@ -0,0 +1,108 @@
+package discovery
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// Deprecation describes a symbol marked deprecated by a "Deprecated:"
+// paragraph in its doc comment
+type Deprecation struct {
+	Note        string // Text of the paragraph after "Deprecated:"
+	Replacement string // Suggested replacement, e.g. "NewClient", empty if none is named
+}
+
+// DeprecatedSymbol is an entry of the deprecated API index
+type DeprecatedSymbol struct {
+	Package  *PackageInfo
+	Name     string // Symbol name, "Type.Method" or "Type.Field" for members
+	Kind     string // package, func, method, type, field, const or var
+	Anchor   string // Heading anchor on the package's API reference page
+	Exported bool   // The symbol and, for members, its type are exported
+	*Deprecation
+}
+
+// replacementPattern matches the usual ways of naming a replacement, e.g.
+// "Use NewClient instead", "replaced by [Client.Do]" or "in favor of x.Y"
+var replacementPattern = regexp.MustCompile(`(?i)\b(?:use|replaced by|in favou?r of)\s+\[?\x60?([A-Za-z_][\w.]*\w)`)
+
+// parseDeprecation finds the "Deprecated:" paragraph of a doc comment, or
+// returns nil if the comment has none
+func parseDeprecation(doc string) *Deprecation {
+	for _, paragraph := range strings.Split(strings.ReplaceAll(doc, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		note, ok := strings.CutPrefix(paragraph, "Deprecated:")
+		if !ok {
+			continue
+		}
+
+		deprecation := &Deprecation{Note: strings.Join(strings.Fields(note), " ")}
+		if match := replacementPattern.FindStringSubmatch(deprecation.Note); match != nil {
+			deprecation.Replacement = match[1]
+		}
+		return deprecation
+	}
+	return nil
+}
+
+// DeprecatedSymbols lists every deprecated package, symbol and struct field
+// of the given packages
+func DeprecatedSymbols(packages []*PackageInfo) []*DeprecatedSymbol {
+	var symbols []*DeprecatedSymbol
+	add := func(pkg *PackageInfo, name, kind, heading string, deprecation *Deprecation) {
+		if deprecation == nil {
+			return
+		}
+		exported := true
+		if kind != "package" {
+			for _, part := range strings.Split(name, ".") {
+				exported = exported && token.IsExported(part)
+			}
+		}
+		symbols = append(symbols, &DeprecatedSymbol{
+			Package:     pkg,
+			Name:        name,
+			Kind:        kind,
+			Anchor:      headingAnchor(heading),
+			Exported:    exported,
+			Deprecation: deprecation,
+		})
+	}
+
+	for _, pkg := range packages {
+		add(pkg, pkg.Name, "package", pkg.Name+" API", pkg.Deprecation)
+
+		for _, value := range pkg.Constants {
+			add(pkg, strings.Join(value.Names, ", "), "const", strings.Join(value.Names, ", "), value.Deprecation)
+		}
+		for _, value := range pkg.Variables {
+			add(pkg, strings.Join(value.Names, ", "), "var", strings.Join(value.Names, ", "), value.Deprecation)
+		}
+
+		for _, typ := range pkg.Types {
+			add(pkg, typ.Name, "type", typ.Name, typ.Deprecation)
+			for _, value := range typ.Consts {
+				add(pkg, strings.Join(value.Names, ", "), "const", typ.Name, value.Deprecation)
+			}
+			for _, value := range typ.Vars {
+				add(pkg, strings.Join(value.Names, ", "), "var", typ.Name, value.Deprecation)
+			}
+			for _, field := range typ.Fields {
+				add(pkg, typ.Name+"."+field.Name, "field", typ.Name, field.Deprecation)
+			}
+			for _, fn := range typ.Funcs {
+				add(pkg, fn.Name, "func", fn.Name, fn.Deprecation)
+			}
+			for _, method := range typ.Methods {
+				add(pkg, typ.Name+"."+method.Name, "method", typ.Name+"."+method.Name, method.Deprecation)
+			}
+		}
+
+		for _, fn := range pkg.Functions {
+			add(pkg, fn.Name, "func", fn.Name, fn.Deprecation)
+		}
+	}
+
+	return symbols
+}
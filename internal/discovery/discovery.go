@@ -1,53 +1,117 @@
 package discovery
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/doc"
-	"go/parser"
+	"go/printer"
 	"go/token"
-	"os"
+	"go/types"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
+	"github.com/kolosys/proton/internal/cache"
 	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/projectfs"
 )
 
-// PackageInfo contains information about a discovered Go package
+// PackageInfo contains information about a discovered Go package.
+//
+// PackageInfo is stored in the discovery cache as JSON. The AST, go/doc and
+// go/types references are not serialized and are nil for cached packages, so
+// templates must use the plain fields instead.
 type PackageInfo struct {
-	Name        string
-	Path        string
-	ImportPath  string
-	Description string
-	Doc         *doc.Package
-	Functions   []*EnhancedFunc
-	Types       []*EnhancedType
-	Variables   []*doc.Value
-	Constants   []*doc.Value
-	Examples    []*doc.Example
-	Files       []string
+	Name          string
+	Path          string
+	ImportPath    string
+	Slug          string // File-system friendly identifier used for generated pages, unique among the discovered packages
+	ModulePath    string // Path of the module containing the package
+	ModuleVersion string // Latest release tag of that module, empty if untagged
+	Description   string
+	Documentation string         // Full package doc comment
+	Doc           *doc.Package   `json:"-"`
+	Package       *types.Package `json:"-"` // Type-checked package, nil if type-checking failed
+	Functions     []*EnhancedFunc
+	Types         []*EnhancedType
+	Variables     []*EnhancedValue
+	Constants     []*EnhancedValue
+	Examples      []*Example
+	Files         []string
+	Platforms     []string          // Platforms the package builds on, nil if all configured platforms
+	Imports       map[string]string // Import paths by the name the package's files refer to them with
+	Deprecation   *Deprecation      // Set if the package clause is deprecated
+	Defaults      map[string]string // Values registered with SetDefault by lowercased key
+	Cobra         *CobraFacts       // Cobra commands and flags, nil if the package defines none
+	Operations    *OperationsFacts  // Flags of the flag package and environment variables, nil if the package reads none
 }
 
 // EnhancedFunc extends doc.Func with additional parameter and return information
 type EnhancedFunc struct {
-	*doc.Func
+	*doc.Func   `json:"-"`
+	Name        string
+	Recv        string // Receiver type for methods, empty for functions
+	Exported    bool
+	TypeParams  []*TypeParam
 	Params      []*Parameter
 	Results     []*Result
 	ExampleCode string
-	Declaration string // Clean formatted function declaration
-	Doc         string // Enhanced documentation (may override doc.Func.Doc)
+	Examples    []*Example   // Testable examples of the function or method
+	Declaration string       // Clean formatted function declaration
+	Signature   string       // Type parameters, parameter and result types without names, e.g. "[T any](string, ...T) (int, error)"
+	Doc         string       // Enhanced documentation (may override doc.Func.Doc)
+	RawDoc      string       // Doc comment text before the Parameters and Returns sections
+	Object      types.Object `json:"-"` // Resolved *types.Func, nil without type information
+	Platforms   []string     // Platforms declaring the function, nil if all configured platforms
+	Deprecation *Deprecation // Set if the function is deprecated
 }
 
 // EnhancedType extends doc.Type with enhanced field information
 type EnhancedType struct {
-	*doc.Type
+	*doc.Type   `json:"-"`
+	Name        string
+	Exported    bool
+	TypeParams  []*TypeParam
 	Fields      []*Field
 	Methods     []*EnhancedFunc
 	Funcs       []*EnhancedFunc
-	TypeKind    string // struct, interface, type alias, etc.
-	Declaration string // Clean formatted declaration
-	Doc         string // Enhanced documentation (may override doc.Type.Doc)
-	ExampleCode string // Usage example code
+	Consts      []*EnhancedValue // Constants of the type, e.g. enum values
+	Vars        []*EnhancedValue // Variables of the type
+	TypeKind    string           // struct, interface, constraint, type alias, etc.
+	Declaration string           // Clean formatted declaration
+	Doc         string           // Enhanced documentation (may override doc.Type.Doc)
+	RawDoc      string           // Doc comment text in go/doc/comment syntax
+	ExampleCode string           // Usage example code
+	Examples    []*Example       // Testable examples of the type
+	Object      types.Object     `json:"-"` // Resolved *types.TypeName, nil without type information
+	Platforms   []string         // Platforms declaring the type, nil if all configured platforms
+	Deprecation *Deprecation     // Set if the type is deprecated
+
+	// Method keys of the method sets of T and *T, for interfaces the methods
+	// they require. Empty without type information and for generic types.
+	MethodSet        []string
+	PointerMethodSet []string
+
+	// Methods and fields promoted through embedded fields or, for
+	// interfaces, methods of embedded interfaces
+	PromotedMethods []*PromotedMember
+	PromotedFields  []*PromotedMember
+
+	// Methods declared by an interface and the interfaces and type set
+	// elements it embeds, in declaration order
+	InterfaceMethods []*EnhancedFunc
+	InterfaceEmbeds  []string
+
+	Implements    []*Implementation `json:"-"` // Interfaces satisfied by a concrete type
+	ImplementedBy []*Implementation `json:"-"` // Types of the project satisfying an interface
+}
+
+// TypeParam represents a type parameter of a generic function or type
+type TypeParam struct {
+	Name       string
+	Constraint string
 }
 
 // Parameter represents a function parameter
@@ -66,10 +130,50 @@ type Result struct {
 
 // Field represents a struct field
 type Field struct {
-	Name string
-	Type string
-	Tag  string
-	Doc  string
+	Name        string // Type name for embedded fields, e.g. "Reader" for "*io.Reader"
+	Embedded    bool
+	Exported    bool
+	Type        string
+	Tag         string       // Raw tag literal including its quotes
+	Tags        []*StructTag // Parsed keys of the tag
+	Doc         string
+	RawDoc      string       // Doc or line comment text in go/doc/comment syntax
+	Object      types.Object `json:"-"` // Resolved *types.Var, nil without type information
+	Deprecation *Deprecation // Set if the field is deprecated
+}
+
+// EnhancedValue extends doc.Value with its formatted declaration
+type EnhancedValue struct {
+	*doc.Value  `json:"-"`
+	Names       []string
+	Exported    bool // The first name is exported
+	Doc         string
+	Declaration string        // Formatted const or var declaration, comments included
+	Entries     []*ValueEntry // Exported names of the declaration
+	Iota        bool          // The declaration is a const group using iota
+	Deprecation *Deprecation  // Set if the declaration is deprecated
+}
+
+// ValueEntry is a single name declared by a const or var declaration
+type ValueEntry struct {
+	Name     string
+	Exported bool
+	Type     string // Type of the name, e.g. "Weekday" or "untyped int", empty without type information
+	Value    string // Computed value of a constant, empty for variables
+	Doc      string // Doc or line comment of the spec declaring the name
+}
+
+// Example is a testable example function of a package
+type Example struct {
+	Name        string // Name without the "Example" prefix, e.g. "Buffer_Write_basic"
+	Symbol      string // Documented function, type or "Type.Method", empty for package examples
+	Suffix      string // Example suffix, e.g. "basic" for ExampleBuffer_Write_basic
+	Doc         string
+	Code        string // Formatted example body, or the whole test file for whole-file examples
+	Output      string // Expected output
+	EmptyOutput bool   // Whether the example expects empty output
+	Unordered   bool   // Whether the output may appear in any order
+	WholeFile   bool   // The example is presented as its entire test file
 }
 
 // Discoverer handles package discovery and parsing
@@ -77,15 +181,51 @@ type Discoverer struct {
 	config      *config.Config
 	projectPath string
 	fileSet     *token.FileSet
+	platforms   []*platformContext
+	modules     []*Module
+	checker     *typeChecker
+	includes    *PatternSet
+	excludes    *PatternSet
+	files       *projectfs.FS // Project tree, the work tree or a git revision
+	cache       *cache.Store  // Discovery cache, nil when caching is disabled
+	index       *SymbolIndex  // Symbols of the discovered packages, set by DiscoverPackages
+
+	sourceHashMu sync.Mutex
+	sourceHashes map[string]string // Memoized source hashes by package directory
+
+	parsedMu sync.Mutex
+	parsed   map[string]*parsedFile // Parsed source files by path, shared with the type checker
 }
 
 // New creates a new package discoverer
 func New(cfg *config.Config, projectPath string) *Discoverer {
-	return &Discoverer{
+	return newDiscoverer(cfg, projectPath, projectfs.Dir(projectPath))
+}
+
+// newDiscoverer creates a package discoverer reading the project from files
+func newDiscoverer(cfg *config.Config, projectPath string, files *projectfs.FS) *Discoverer {
+	d := &Discoverer{
 		config:      cfg,
 		projectPath: projectPath,
 		fileSet:     token.NewFileSet(),
+		platforms:   newPlatformContexts(cfg.Discovery.Build.Platforms, cfg.Discovery.Build.Tags),
+		includes:    NewPatternSet(cfg.Discovery.Packages.IncludePatterns),
+		excludes:    NewPatternSet(cfg.Discovery.Packages.ExcludePatterns),
+		files:       files,
+
+		sourceHashes: make(map[string]string),
+		parsed:       make(map[string]*parsedFile),
+	}
+	// Build constraints are read from the file headers
+	for _, platform := range d.platforms {
+		platform.context.OpenFile = files.Open
 	}
+	if cfg.Cache.Enabled {
+		d.cache = cache.ForProject(cfg, projectPath)
+	}
+	d.modules = d.detectModules()
+	d.checker = newTypeChecker(d.fileSet, d.modules, d.parseDirForTypes)
+	return d
 }
 
 // DiscoverPackages discovers all packages in the project according to configuration
@@ -119,96 +259,86 @@ func (d *Discoverer) DiscoverPackages() ([]*PackageInfo, error) {
 		allPackages = append(allPackages, pkgInfo)
 	}
 
-	return allPackages, nil
-}
-
-// autoDiscoverPackages automatically discovers packages using the configured patterns
-func (d *Discoverer) autoDiscoverPackages() ([]*PackageInfo, error) {
-	// Use parser.ParseDir for better AST comment preservation
-	// This approach preserves all AST comments needed for documentation extraction
+	// Relate types and interfaces across all packages
+	linkImplementations(allPackages)
+	d.assignSlugs(allPackages)
+	d.index = NewSymbolIndex(allPackages, d.config.Discovery.APIGeneration.IndexUnexported)
 
-	// Get all Go files in the project
-	var allPackages []*PackageInfo
-
-	// Walk through the project directory to find packages
-	err := filepath.Walk(d.projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip vendor and test directories unless explicitly included
-		if info.IsDir() {
-			if strings.Contains(path, "/vendor/") ||
-				(strings.Contains(path, "/test/") && !d.config.Discovery.APIGeneration.IncludeTests) {
-				return filepath.SkipDir
+	// Group packages by module for per-module documentation sections
+	for _, module := range d.modules {
+		module.Packages = nil
+		for _, pkg := range allPackages {
+			if pkg.ModulePath == module.Path {
+				module.Packages = append(module.Packages, pkg)
 			}
-			return nil
-		}
-
-		// Only process .go files
-		if !strings.HasSuffix(info.Name(), ".go") {
-			return nil
-		}
-
-		// Skip test files unless explicitly included
-		if strings.HasSuffix(info.Name(), "_test.go") && !d.config.Discovery.APIGeneration.IncludeTests {
-			return nil
 		}
+	}
 
-		// Get the package directory
-		pkgDir := filepath.Dir(path)
+	return allPackages, nil
+}
 
-		// Check if we've already processed this package
-		for _, pkg := range allPackages {
-			if pkg.Path == pkgDir {
-				return nil
-			}
-		}
+// autoDiscoverPackages automatically discovers packages using the configured patterns.
+// The project is walked once and the matching packages are parsed by a bounded
+// pool of workers; results keep the sorted order of the package paths.
+func (d *Discoverer) autoDiscoverPackages() ([]*PackageInfo, error) {
+	pkgPaths, err := d.packageDirs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk project directory: %w", err)
+	}
 
-		// Check if package should be excluded
-		relPath, err := filepath.Rel(d.projectPath, pkgDir)
-		if err != nil {
-			return nil
+	var selected []string
+	for _, pkgPath := range pkgPaths {
+		if d.shouldIncludePackage(pkgPath) {
+			selected = append(selected, pkgPath)
 		}
+	}
 
-		if d.shouldExcludePackage(relPath) {
-			return nil
-		}
+	results := make([]*PackageInfo, len(selected))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < d.concurrency(len(selected)); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				pkgInfo, err := d.parsePackage(filepath.FromSlash(selected[i]))
+				if err != nil {
+					// Log error but continue with other packages
+					fmt.Printf("Warning: failed to parse package %s: %v\n", selected[i], err)
+					continue
+				}
+				results[i] = pkgInfo
+			}
+		}()
+	}
 
-		// Parse the package
-		pkgInfo, err := d.parsePackage(relPath)
-		if err != nil {
-			// Log error but continue with other packages
-			fmt.Printf("Warning: failed to parse package %s: %v\n", relPath, err)
-			return nil
-		}
+	for i := range selected {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
+	allPackages := make([]*PackageInfo, 0, len(results))
+	for _, pkgInfo := range results {
 		if pkgInfo != nil {
 			allPackages = append(allPackages, pkgInfo)
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to walk project directory: %w", err)
 	}
 
 	return allPackages, nil
 }
 
-// shouldExcludePackage checks if a package should be excluded based on patterns
-func (d *Discoverer) shouldExcludePackage(pkgPath string) bool {
-	for _, pattern := range d.config.Discovery.Packages.ExcludePatterns {
-		if matched, _ := filepath.Match(pattern, pkgPath); matched {
-			return true
-		}
-		// Also check if the pattern matches any part of the path
-		if strings.Contains(pkgPath, strings.Trim(pattern, "*")) {
-			return true
-		}
+// concurrency returns the number of discovery workers for the given number of packages
+func (d *Discoverer) concurrency(packages int) int {
+	workers := d.config.Discovery.MaxConcurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
 	}
-	return false
+	if workers > packages {
+		workers = packages
+	}
+	return workers
 }
 
 // parsePackage parses a single package from a given path
@@ -221,38 +351,71 @@ func (d *Discoverer) parsePackage(pkgPath string) (*PackageInfo, error) {
 		fullPath = pkgPath
 	}
 
-	// Parse package directory with all comment modes
-	pkgs, err := parser.ParseDir(d.fileSet, fullPath, func(info os.FileInfo) bool {
-		name := info.Name()
-		// Skip test files unless explicitly included
-		if strings.HasSuffix(name, "_test.go") && !d.config.Discovery.APIGeneration.IncludeTests {
-			return false
+	// Select the package files by evaluating build constraints for every platform
+	pkgName, files, err := d.selectPackageFiles(fullPath, d.config.Discovery.APIGeneration.IncludeTests)
+	if err != nil {
+		return nil, err
+	}
+
+	// Test files of the package and its external test package hold the
+	// testable examples
+	var testFiles []*sourceFile
+	if d.config.Discovery.APIGeneration.IncludeExamples {
+		if testFiles, err = d.selectExampleFiles(fullPath, pkgName, files); err != nil {
+			return nil, err
 		}
-		return strings.HasSuffix(name, ".go")
-	}, parser.ParseComments|parser.AllErrors)
+	}
 
+	// Unchanged packages are loaded from the cache instead of being parsed
+	cached, cacheKey := d.cachedPackage(fullPath, append(files[:len(files):len(files)], testFiles...))
+	if cached != nil {
+		return cached, nil
+	}
+
+	// Parse package files with all comment modes
+	astPkg, err := d.parseSourceFiles(pkgName, files)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse directory %s: %w", fullPath, err)
 	}
 
-	// Find the main package (non-test package)
-	var mainPkg *ast.Package
-	for name, pkg := range pkgs {
-		if !strings.HasSuffix(name, "_test") {
-			mainPkg = pkg
-			break
-		}
+	pkgInfo, err := d.parseASTPackage(astPkg, fullPath, files, testFiles)
+	if err != nil {
+		return nil, err
 	}
 
-	if mainPkg == nil {
-		return nil, fmt.Errorf("no non-test package found in %s", fullPath)
+	if cacheKey != "" {
+		d.storePackage(cacheKey, pkgInfo)
 	}
 
-	return d.parseASTPackage(mainPkg, fullPath)
+	return pkgInfo, nil
 }
 
 // parseASTPackage creates PackageInfo from an AST package
-func (d *Discoverer) parseASTPackage(astPkg *ast.Package, pkgPath string) (*PackageInfo, error) {
+func (d *Discoverer) parseASTPackage(astPkg *ast.Package, pkgPath string, sourceFiles, testFiles []*sourceFile) (*PackageInfo, error) {
+	// Determine import path and module
+	importPath := d.getImportPath(pkgPath)
+	var modulePath, moduleVersion string
+	if module := d.moduleFor(pkgPath); module != nil {
+		modulePath, moduleVersion = module.Path, module.Version
+	}
+
+	// Type-check before doc.New, which takes ownership of the AST and trims it
+	typesPkg := d.typeCheck(importPath, d.primaryPlatformFiles(astPkg, sourceFiles))
+	platforms := d.symbolPlatforms(astPkg, sourceFiles)
+	imports := packageImports(astPkg, typesPkg)
+	defaults := collectDefaults(d.fileSet, astPkg, typesPkg)
+	cobra := collectCobraFacts(d.fileSet, astPkg, importPath, imports, typesPkg)
+	operations := collectOperationsFacts(d.fileSet, astPkg, d.projectPath, imports, typesPkg)
+
+	// Extract examples before doc.New strips function bodies
+	var examples []*Example
+	if d.config.Discovery.APIGeneration.IncludeExamples {
+		var err error
+		if examples, err = d.extractExamples(astPkg, testFiles); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create doc package - always use AllDecls for better documentation extraction
 	docPkg := doc.New(astPkg, "./", doc.AllDecls)
 
@@ -268,71 +431,79 @@ func (d *Discoverer) parseASTPackage(astPkg *ast.Package, pkgPath string) (*Pack
 
 	// Get file list
 	var files []string
-	for filename := range astPkg.Files {
-		files = append(files, filename)
+	for _, file := range sourceFiles {
+		files = append(files, file.path)
 	}
 
-	// Determine import path
-	importPath := d.getImportPath(pkgPath)
-
-	// Filter and enhance functions to only include public ones (starting with uppercase)
-	var enhancedFuncs []*EnhancedFunc
-	for _, fn := range docPkg.Funcs {
-		if len(fn.Name) > 0 && strings.ToUpper(fn.Name[:1]) == fn.Name[:1] {
-			enhancedFuncs = append(enhancedFuncs, d.enhanceFunction(fn, astPkg))
-		}
-	}
-
-	// Filter and enhance types to only include public ones (starting with uppercase)
+	// Filter and enhance types to only include documented ones
 	var enhancedTypes []*EnhancedType
 	for _, typ := range docPkg.Types {
-		if len(typ.Name) > 0 && strings.ToUpper(typ.Name[:1]) == typ.Name[:1] {
-			enhancedTypes = append(enhancedTypes, d.enhanceType(typ, astPkg))
+		if d.documented(typ.Name) {
+			enhancedTypes = append(enhancedTypes, d.enhanceType(typ, astPkg, typesPkg, platforms))
 		}
 	}
 
-	// Filter variables to only include public ones (starting with uppercase)
-	var publicVars []*doc.Value
-	for _, v := range docPkg.Vars {
-		if len(v.Names) > 0 && len(v.Names[0]) > 0 && strings.ToUpper(v.Names[0][:1]) == v.Names[0][:1] {
-			publicVars = append(publicVars, v)
+	// Constructors and values of undocumented types have no type section,
+	// list them with the package
+	funcs, vars, consts := docPkg.Funcs, docPkg.Vars, docPkg.Consts
+	for _, typ := range docPkg.Types {
+		if !d.documented(typ.Name) {
+			funcs = append(funcs, typ.Funcs...)
+			vars = append(vars, typ.Vars...)
+			consts = append(consts, typ.Consts...)
 		}
 	}
 
-	// Filter constants to only include public ones (starting with uppercase)
-	var publicConsts []*doc.Value
-	for _, c := range docPkg.Consts {
-		if len(c.Names) > 0 && len(c.Names[0]) > 0 && strings.ToUpper(c.Names[0][:1]) == c.Names[0][:1] {
-			publicConsts = append(publicConsts, c)
+	// Filter and enhance functions to only include documented ones
+	var enhancedFuncs []*EnhancedFunc
+	for _, fn := range funcs {
+		if d.documented(fn.Name) {
+			enhancedFunc := d.enhanceFunction(fn, astPkg)
+			enhancedFunc.Object = lookupObject(typesPkg, fn.Name)
+			enhancedFunc.Platforms = platforms[fn.Name]
+			enhancedFuncs = append(enhancedFuncs, enhancedFunc)
 		}
 	}
 
+	publicVars := d.enhanceValues(vars, astPkg, typesPkg)
+	publicConsts := d.enhanceValues(consts, astPkg, typesPkg)
+
 	pkgInfo := &PackageInfo{
-		Name:        astPkg.Name,
-		Path:        pkgPath,
-		ImportPath:  importPath,
-		Description: description,
-		Doc:         docPkg,
-		Functions:   enhancedFuncs,
-		Types:       enhancedTypes,
-		Variables:   publicVars,
-		Constants:   publicConsts,
-		Files:       files,
-	}
-
-	// Extract examples if enabled
-	if d.config.Discovery.APIGeneration.IncludeExamples {
-		examples := d.extractExamples(astPkg)
-		pkgInfo.Examples = examples
-	}
+		Name:          astPkg.Name,
+		Path:          pkgPath,
+		ImportPath:    importPath,
+		ModulePath:    modulePath,
+		ModuleVersion: moduleVersion,
+		Description:   description,
+		Documentation: docPkg.Doc,
+		Deprecation:   parseDeprecation(docPkg.Doc),
+		Doc:           docPkg,
+		Package:       typesPkg,
+		Functions:     enhancedFuncs,
+		Types:         enhancedTypes,
+		Variables:     publicVars,
+		Constants:     publicConsts,
+		Files:         files,
+		Platforms:     d.packagePlatforms(sourceFiles),
+		Imports:       imports,
+		Defaults:      defaults,
+		Cobra:         cobra,
+		Operations:    operations,
+	}
+	associateExamples(pkgInfo, examples)
 
 	return pkgInfo, nil
 }
 
-// getImportPath determines the import path for a package
+// getImportPath determines the import path for a package from the module containing it
 func (d *Discoverer) getImportPath(pkgPath string) string {
+	modulePath, moduleDir := d.config.Repository.ImportPath, d.projectPath
+	if module := d.moduleFor(pkgPath); module != nil {
+		modulePath, moduleDir = module.Path, module.Dir
+	}
+
 	// Try to determine import path relative to the module root
-	relPath, err := filepath.Rel(d.projectPath, pkgPath)
+	relPath, err := filepath.Rel(moduleDir, pkgPath)
 	if err != nil {
 		return ""
 	}
@@ -340,22 +511,58 @@ func (d *Discoverer) getImportPath(pkgPath string) string {
 	// Clean up the path
 	relPath = filepath.ToSlash(relPath)
 	if relPath == "." {
-		return d.config.Repository.ImportPath
+		return modulePath
 	}
 
-	return d.config.Repository.ImportPath + "/" + relPath
+	return modulePath + "/" + relPath
 }
 
-// extractExamples extracts example functions from the package
-func (d *Discoverer) extractExamples(astPkg *ast.Package) []*doc.Example {
-	var examples []*doc.Example
+// documented reports whether a symbol is documented: exported symbols always
+// are, unexported ones when include_unexported is set
+func (d *Discoverer) documented(name string) bool {
+	return token.IsExported(name) || d.config.Discovery.APIGeneration.IncludeUnexported
+}
 
-	for _, file := range astPkg.Files {
-		fileExamples := doc.Examples(file)
-		examples = append(examples, fileExamples...)
+// enhanceValues enhances the values whose first name is documented
+func (d *Discoverer) enhanceValues(values []*doc.Value, astPkg *ast.Package, typesPkg *types.Package) []*EnhancedValue {
+	var enhanced []*EnhancedValue
+	for _, value := range values {
+		if len(value.Names) > 0 && d.documented(value.Names[0]) {
+			enhanced = append(enhanced, d.enhanceValue(value, astPkg, typesPkg))
+		}
+	}
+	return enhanced
+}
+
+// enhanceValue formats a const or var declaration for documentation
+func (d *Discoverer) enhanceValue(value *doc.Value, astPkg *ast.Package, typesPkg *types.Package) *EnhancedValue {
+	enhanced := &EnhancedValue{
+		Value:       value,
+		Names:       value.Names,
+		Exported:    token.IsExported(value.Names[0]),
+		Doc:         value.Doc,
+		Entries:     valueEntries(value.Decl, typesPkg, d.documented),
+		Iota:        usesIota(value.Decl),
+		Deprecation: parseDeprecation(value.Doc),
+	}
+
+	// The doc comment is rendered separately, print the declaration without it
+	decl := *value.Decl
+	decl.Doc = nil
+
+	node := &printer.CommentedNode{
+		Node:     &decl,
+		Comments: declComments(d.fileSet, astPkg, value.Decl),
+	}
+
+	// Align with spaces and indent like the other declarations
+	var buf bytes.Buffer
+	printerConfig := &printer.Config{Mode: printer.UseSpaces, Tabwidth: 4}
+	if err := printerConfig.Fprint(&buf, d.fileSet, node); err == nil {
+		enhanced.Declaration = strings.TrimSpace(buf.String())
 	}
 
-	return examples
+	return enhanced
 }
 
 // GetPackagesByCategory categorizes packages for easier documentation generation
@@ -394,6 +601,9 @@ func (d *Discoverer) determinePackageCategory(pkg *PackageInfo) string {
 func (d *Discoverer) enhanceFunction(fn *doc.Func, astPkg *ast.Package) *EnhancedFunc {
 	enhanced := &EnhancedFunc{
 		Func:        fn,
+		Name:        fn.Name,
+		Recv:        fn.Recv,
+		Exported:    token.IsExported(fn.Name),
 		Params:      []*Parameter{},
 		Results:     []*Result{},
 		ExampleCode: d.generateExampleCode(fn),
@@ -402,18 +612,9 @@ func (d *Discoverer) enhanceFunction(fn *doc.Func, astPkg *ast.Package) *Enhance
 	}
 
 	// Find the function declaration in the AST
-	var funcDecl *ast.FuncDecl
-	for _, file := range astPkg.Files {
-		ast.Inspect(file, func(n ast.Node) bool {
-			if f, ok := n.(*ast.FuncDecl); ok && f.Name.Name == fn.Name {
-				funcDecl = f
-				return false
-			}
-			return true
-		})
-		if funcDecl != nil {
-			break
-		}
+	funcDecl := fn.Decl
+	if funcDecl == nil {
+		funcDecl = findFuncDecl(astPkg, fn)
 	}
 
 	// Use the original documentation from doc.Func for parameter/return parsing
@@ -421,6 +622,8 @@ func (d *Discoverer) enhanceFunction(fn *doc.Func, astPkg *ast.Package) *Enhance
 
 	// For the main function description, show only the part before Parameters/Returns sections
 	enhanced.Doc = d.extractMainDescription(fullDoc)
+	enhanced.RawDoc = mainDocText(fullDoc)
+	enhanced.Deprecation = parseDeprecation(fullDoc)
 
 	if funcDecl == nil {
 		return enhanced
@@ -428,6 +631,8 @@ func (d *Discoverer) enhanceFunction(fn *doc.Func, astPkg *ast.Package) *Enhance
 
 	// Generate clean function declaration
 	enhanced.Declaration = d.generateFunctionDeclaration(fn, funcDecl)
+	enhanced.TypeParams = d.extractTypeParams(funcDecl.Type.TypeParams)
+	enhanced.Signature = d.formatSignature(funcDecl.Type)
 
 	// Extract parameters
 	if funcDecl.Type.Params != nil {
@@ -470,10 +675,40 @@ func (d *Discoverer) enhanceFunction(fn *doc.Func, astPkg *ast.Package) *Enhance
 	return enhanced
 }
 
+// findFuncDecl looks up the declaration of a function or method. Functions
+// and the methods of different types may share a name, so methods are
+// matched by their receiver's base type too.
+func findFuncDecl(astPkg *ast.Package, fn *doc.Func) *ast.FuncDecl {
+	recv := strings.TrimPrefix(fn.Recv, "*")
+	if i := strings.Index(recv, "["); i >= 0 {
+		recv = recv[:i]
+	}
+
+	for _, file := range sortedFiles(astPkg.Files) {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Name.Name != fn.Name {
+				continue
+			}
+			hasRecv := funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0
+			if hasRecv != (recv != "") {
+				continue
+			}
+			if hasRecv && typeBaseName(funcDecl.Recv.List[0].Type) != recv {
+				continue
+			}
+			return funcDecl
+		}
+	}
+	return nil
+}
+
 // enhanceType extracts detailed field and method information from a type
-func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package) *EnhancedType {
+func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package, typesPkg *types.Package, platforms map[string][]string) *EnhancedType {
 	enhanced := &EnhancedType{
 		Type:        typ,
+		Name:        typ.Name,
+		Exported:    token.IsExported(typ.Name),
 		Fields:      []*Field{},
 		Methods:     []*EnhancedFunc{},
 		Funcs:       []*EnhancedFunc{},
@@ -481,10 +716,14 @@ func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package) *EnhancedTy
 		Declaration: "",
 		Doc:         typ.Doc, // Start with doc.Type.Doc
 		ExampleCode: "",
+		Object:      lookupObject(typesPkg, typ.Name),
+		Platforms:   platforms[typ.Name],
 	}
 
 	// Use custom AST traversal to extract type documentation
 	enhanced.Doc = d.extractTypeDocumentation(typ.Name, astPkg)
+	enhanced.RawDoc = typ.Doc
+	enhanced.Deprecation = parseDeprecation(typ.Doc)
 
 	// Find the type declaration in the AST
 	var typeSpec *ast.TypeSpec
@@ -505,11 +744,20 @@ func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package) *EnhancedTy
 		return enhanced
 	}
 
+	// Types in grouped declarations may carry their own doc comment
+	if enhanced.RawDoc == "" && typeSpec.Doc != nil {
+		enhanced.RawDoc = typeSpec.Doc.Text()
+	}
+
+	// Type parameters are part of the declared name, e.g. "List[T any]"
+	enhanced.TypeParams = d.extractTypeParams(typeSpec.TypeParams)
+	declName := typ.Name + d.formatTypeParams(typeSpec.TypeParams)
+
 	// Determine type kind and generate clean declaration
 	switch t := typeSpec.Type.(type) {
 	case *ast.StructType:
 		enhanced.TypeKind = "struct"
-		enhanced.Declaration = d.generateStructDeclaration(typ.Name, t)
+		enhanced.Declaration = d.generateStructDeclaration(declName, t)
 		enhanced.ExampleCode = d.generateTypeExample(typ, typeSpec)
 		// Extract struct documentation from AST if doc.Type.Doc is empty
 		if enhanced.Doc == "" && typeSpec.Doc != nil {
@@ -517,7 +765,11 @@ func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package) *EnhancedTy
 		}
 	case *ast.InterfaceType:
 		enhanced.TypeKind = "interface"
-		enhanced.Declaration = d.generateInterfaceDeclaration(typ.Name, t)
+		if d.isConstraintInterface(t) {
+			enhanced.TypeKind = "constraint"
+		}
+		enhanced.Declaration = d.generateInterfaceDeclaration(declName, t)
+		enhanced.InterfaceMethods, enhanced.InterfaceEmbeds = d.interfaceElements(t)
 		enhanced.ExampleCode = d.generateTypeExample(typ, typeSpec)
 		// Extract interface documentation from AST if doc.Type.Doc is empty
 		if enhanced.Doc == "" && typeSpec.Doc != nil {
@@ -525,7 +777,7 @@ func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package) *EnhancedTy
 		}
 	default:
 		enhanced.TypeKind = "type"
-		enhanced.Declaration = fmt.Sprintf("type %s %s", typ.Name, d.formatType(typeSpec.Type))
+		enhanced.Declaration = fmt.Sprintf("type %s %s", declName, d.formatType(typeSpec.Type))
 		enhanced.ExampleCode = d.generateTypeExample(typ, typeSpec)
 		// Extract type documentation from AST if doc.Type.Doc is empty
 		if enhanced.Doc == "" && typeSpec.Doc != nil {
@@ -533,6 +785,20 @@ func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package) *EnhancedTy
 		}
 	}
 
+	// Type aliases declare with "=" but keep their fields and example from above
+	if typeSpec.Assign.IsValid() {
+		enhanced.TypeKind = "alias"
+		enhanced.Declaration = fmt.Sprintf("type %s = %s", declName, d.formatType(typeSpec.Type))
+	}
+
+	// Resolved type information is authoritative for the kind, e.g. for
+	// interfaces embedding constraints declared elsewhere
+	if kind := typeKindOf(enhanced.Object); kind != "" {
+		enhanced.TypeKind = kind
+	}
+	d.resolveMethodSets(enhanced)
+	d.resolvePromoted(enhanced)
+
 	// Extract fields for struct types
 	if structType, ok := typeSpec.Type.(*ast.StructType); ok {
 		for _, field := range structType.Fields.List {
@@ -544,20 +810,34 @@ func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package) *EnhancedTy
 
 			if len(field.Names) > 0 {
 				for _, name := range field.Names {
+					if !d.documented(name.Name) {
+						continue
+					}
 					enhanced.Fields = append(enhanced.Fields, &Field{
-						Name: name.Name,
-						Type: fieldType,
-						Tag:  fieldTag,
-						Doc:  d.extractFieldDoc(field),
+						Name:        name.Name,
+						Exported:    name.IsExported(),
+						Type:        fieldType,
+						Tag:         fieldTag,
+						Tags:        parseStructTag(fieldTag),
+						Doc:         d.extractFieldDoc(field),
+						RawDoc:      fieldDocText(field),
+						Object:      lookupField(enhanced.Object, name.Name),
+						Deprecation: parseDeprecation(field.Doc.Text()),
 					})
 				}
-			} else {
+			} else if embedded := typeBaseName(field.Type); d.documented(embedded) {
 				// Embedded field
 				enhanced.Fields = append(enhanced.Fields, &Field{
-					Name: "",
-					Type: fieldType,
-					Tag:  fieldTag,
-					Doc:  d.extractFieldDoc(field),
+					Name:        embedded,
+					Embedded:    true,
+					Exported:    token.IsExported(embedded),
+					Type:        fieldType,
+					Tag:         fieldTag,
+					Tags:        parseStructTag(fieldTag),
+					Doc:         d.extractFieldDoc(field),
+					RawDoc:      fieldDocText(field),
+					Object:      lookupField(enhanced.Object, embedded),
+					Deprecation: parseDeprecation(field.Doc.Text()),
 				})
 			}
 		}
@@ -565,7 +845,12 @@ func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package) *EnhancedTy
 
 	// Enhance methods
 	for _, method := range typ.Methods {
+		if !d.documented(method.Name) {
+			continue
+		}
 		enhancedMethod := d.enhanceFunction(method, astPkg)
+		enhancedMethod.Object = lookupMethod(enhanced.Object, method.Name)
+		enhancedMethod.Platforms = platforms[typ.Name+"."+method.Name]
 		// For interface methods, try to extract documentation from the interface doc
 		if enhanced.TypeKind == "interface" && enhancedMethod.Doc == "" {
 			enhancedMethod.Doc = d.extractInterfaceMethodDoc(typ, method.Name)
@@ -575,9 +860,18 @@ func (d *Discoverer) enhanceType(typ *doc.Type, astPkg *ast.Package) *EnhancedTy
 
 	// Enhance constructor functions
 	for _, fn := range typ.Funcs {
-		enhanced.Funcs = append(enhanced.Funcs, d.enhanceFunction(fn, astPkg))
+		if !d.documented(fn.Name) {
+			continue
+		}
+		enhancedFunc := d.enhanceFunction(fn, astPkg)
+		enhancedFunc.Object = lookupObject(typesPkg, fn.Name)
+		enhancedFunc.Platforms = platforms[fn.Name]
+		enhanced.Funcs = append(enhanced.Funcs, enhancedFunc)
 	}
 
+	enhanced.Consts = d.enhanceValues(typ.Consts, astPkg, typesPkg)
+	enhanced.Vars = d.enhanceValues(typ.Vars, astPkg, typesPkg)
+
 	return enhanced
 }
 
@@ -588,11 +882,31 @@ func (d *Discoverer) formatType(expr ast.Expr) string {
 		return t.Name
 	case *ast.StarExpr:
 		return "*" + d.formatType(t.X)
+	case *ast.BasicLit:
+		return t.Value
+	case *ast.ParenExpr:
+		return "(" + d.formatType(t.X) + ")"
 	case *ast.ArrayType:
 		if t.Len == nil {
 			return "[]" + d.formatType(t.Elt)
 		}
 		return fmt.Sprintf("[%s]%s", d.formatType(t.Len), d.formatType(t.Elt))
+	case *ast.IndexExpr:
+		// Instantiated generic type with a single type argument, e.g. List[int]
+		return fmt.Sprintf("%s[%s]", d.formatType(t.X), d.formatType(t.Index))
+	case *ast.IndexListExpr:
+		// Instantiated generic type with several type arguments, e.g. Map[string, int]
+		args := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			args[i] = d.formatType(index)
+		}
+		return fmt.Sprintf("%s[%s]", d.formatType(t.X), strings.Join(args, ", "))
+	case *ast.UnaryExpr:
+		// Approximation element in a type set, e.g. ~int
+		return t.Op.String() + d.formatType(t.X)
+	case *ast.BinaryExpr:
+		// Union element in a type set, e.g. ~int | ~string
+		return fmt.Sprintf("%s %s %s", d.formatType(t.X), t.Op, d.formatType(t.Y))
 	case *ast.MapType:
 		return fmt.Sprintf("map[%s]%s", d.formatType(t.Key), d.formatType(t.Value))
 	case *ast.ChanType:
@@ -605,17 +919,201 @@ func (d *Discoverer) formatType(expr ast.Expr) string {
 		return prefix + " " + d.formatType(t.Value)
 	case *ast.FuncType:
 		return "func" + d.formatFuncSignature(t)
+	case *ast.StructType:
+		return d.formatInlineStruct(t)
 	case *ast.InterfaceType:
-		return "interface{}"
+		return d.formatInlineInterface(t)
 	case *ast.SelectorExpr:
 		return d.formatType(t.X) + "." + t.Sel.Name
 	case *ast.Ellipsis:
+		if t.Elt == nil {
+			// Array length inferred from the composite literal, e.g. [...]int
+			return "..."
+		}
 		return "..." + d.formatType(t.Elt)
 	default:
 		return fmt.Sprintf("%T", expr)
 	}
 }
 
+// formatInlineStruct formats an anonymous struct type on a single line
+func (d *Discoverer) formatInlineStruct(structType *ast.StructType) string {
+	if structType.Fields == nil || len(structType.Fields.List) == 0 {
+		return "struct{}"
+	}
+
+	var fields []string
+	for _, field := range structType.Fields.List {
+		fieldType := d.formatType(field.Type)
+		if field.Tag != nil {
+			fieldType += " " + field.Tag.Value
+		}
+
+		if len(field.Names) == 0 {
+			fields = append(fields, fieldType)
+			continue
+		}
+
+		names := make([]string, len(field.Names))
+		for i, name := range field.Names {
+			names[i] = name.Name
+		}
+		fields = append(fields, strings.Join(names, ", ")+" "+fieldType)
+	}
+
+	return "struct{ " + strings.Join(fields, "; ") + " }"
+}
+
+// formatInlineInterface formats an anonymous interface type on a single line
+func (d *Discoverer) formatInlineInterface(interfaceType *ast.InterfaceType) string {
+	if interfaceType.Methods == nil || len(interfaceType.Methods.List) == 0 {
+		return "interface{}"
+	}
+
+	var elems []string
+	for _, method := range interfaceType.Methods.List {
+		if len(method.Names) > 0 {
+			if funcType, ok := method.Type.(*ast.FuncType); ok {
+				elems = append(elems, method.Names[0].Name+d.formatFuncSignature(funcType))
+			}
+			continue
+		}
+		// Embedded interface or type set element
+		elems = append(elems, d.formatType(method.Type))
+	}
+
+	return "interface{ " + strings.Join(elems, "; ") + " }"
+}
+
+// formatTypeParams formats a type parameter list, e.g. "[K comparable, V any]"
+func (d *Discoverer) formatTypeParams(typeParams *ast.FieldList) string {
+	if typeParams == nil || len(typeParams.List) == 0 {
+		return ""
+	}
+
+	var groups []string
+	for _, param := range typeParams.List {
+		names := make([]string, len(param.Names))
+		for i, name := range param.Names {
+			names[i] = name.Name
+		}
+		groups = append(groups, strings.Join(names, ", ")+" "+d.formatType(param.Type))
+	}
+
+	return "[" + strings.Join(groups, ", ") + "]"
+}
+
+// formatSignature formats the type parameters, parameter types and result
+// types of a function without their names, so signatures compare equal when
+// only names differ
+func (d *Discoverer) formatSignature(funcType *ast.FuncType) string {
+	fieldTypes := func(fields *ast.FieldList) []string {
+		var list []string
+		if fields == nil {
+			return list
+		}
+		for _, field := range fields.List {
+			for range max(len(field.Names), 1) {
+				list = append(list, d.formatType(field.Type))
+			}
+		}
+		return list
+	}
+
+	signature := d.formatTypeParams(funcType.TypeParams) + "(" + strings.Join(fieldTypes(funcType.Params), ", ") + ")"
+	switch results := fieldTypes(funcType.Results); len(results) {
+	case 0:
+	case 1:
+		signature += " " + results[0]
+	default:
+		signature += " (" + strings.Join(results, ", ") + ")"
+	}
+	return signature
+}
+
+// interfaceElements returns the methods an interface declares and the
+// interfaces and type set elements it embeds
+func (d *Discoverer) interfaceElements(interfaceType *ast.InterfaceType) ([]*EnhancedFunc, []string) {
+	var methods []*EnhancedFunc
+	var embeds []string
+	if interfaceType.Methods == nil {
+		return methods, embeds
+	}
+
+	for _, field := range interfaceType.Methods.List {
+		funcType, ok := field.Type.(*ast.FuncType)
+		if len(field.Names) == 0 || !ok {
+			embeds = append(embeds, d.formatType(field.Type))
+			continue
+		}
+		name := field.Names[0].Name
+		methods = append(methods, &EnhancedFunc{
+			Name:        name,
+			Exported:    token.IsExported(name),
+			Declaration: name + d.formatFuncSignature(funcType),
+			Signature:   d.formatSignature(funcType),
+			Doc:         strings.TrimSpace(field.Doc.Text()),
+			RawDoc:      field.Doc.Text(),
+			Deprecation: parseDeprecation(field.Doc.Text()),
+		})
+	}
+	return methods, embeds
+}
+
+// extractTypeParams converts a type parameter list into individual type parameters
+func (d *Discoverer) extractTypeParams(typeParams *ast.FieldList) []*TypeParam {
+	if typeParams == nil {
+		return nil
+	}
+
+	var params []*TypeParam
+	for _, param := range typeParams.List {
+		constraint := d.formatType(param.Type)
+		for _, name := range param.Names {
+			params = append(params, &TypeParam{
+				Name:       name.Name,
+				Constraint: constraint,
+			})
+		}
+	}
+	return params
+}
+
+// predeclaredConstraintTypes lists predeclared identifiers that may only be
+// embedded in constraint interfaces
+var predeclaredConstraintTypes = map[string]bool{
+	"bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+	"int32": true, "int64": true, "rune": true, "string": true, "uint": true,
+	"uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+}
+
+// isConstraintInterface reports whether an interface declares a type set,
+// which restricts it to use as a type parameter constraint
+func (d *Discoverer) isConstraintInterface(interfaceType *ast.InterfaceType) bool {
+	if interfaceType.Methods == nil {
+		return false
+	}
+
+	for _, method := range interfaceType.Methods.List {
+		if len(method.Names) > 0 {
+			continue
+		}
+
+		switch t := method.Type.(type) {
+		case *ast.UnaryExpr, *ast.BinaryExpr:
+			// ~T approximations and A | B unions
+			return true
+		case *ast.Ident:
+			// Embedded predeclared non-interface types and comparable
+			if predeclaredConstraintTypes[t.Name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // formatFuncSignature formats a function signature
 func (d *Discoverer) formatFuncSignature(funcType *ast.FuncType) string {
 	var result strings.Builder
@@ -800,20 +1298,28 @@ func (d *Discoverer) generateTypeExample(typ *doc.Type, typeSpec *ast.TypeSpec)
 		example.WriteString(fmt.Sprintf("// Create a new %s\n", typ.Name))
 		example.WriteString(fmt.Sprintf("%s := %s{\n", strings.ToLower(typ.Name), typ.Name))
 
-		// Add example field values
-		for i, field := range t.Fields.List {
-			if len(field.Names) > 0 {
+		// Add example field values, skipping fields excluded from the docs
+		var values []string
+		for _, field := range t.Fields.List {
+			if len(field.Names) > 0 && d.documented(field.Names[0].Name) {
 				fieldName := field.Names[0].Name
 				exampleValue := d.generateExampleValue(field.Type)
-				example.WriteString(fmt.Sprintf("    %s: %s,", fieldName, exampleValue))
-				if i < len(t.Fields.List)-1 {
-					example.WriteString("\n")
-				}
+				values = append(values, fmt.Sprintf("    %s: %s,", fieldName, exampleValue))
 			}
 		}
+		example.WriteString(strings.Join(values, "\n"))
 		example.WriteString("\n}")
 
 	case *ast.InterfaceType:
+		if d.isConstraintInterface(t) {
+			// Constraints cannot be implemented, only used to restrict type parameters
+			example.WriteString(fmt.Sprintf("// Example usage of %s as a type constraint\n", typ.Name))
+			example.WriteString(fmt.Sprintf("func process[T %s](value T) T {\n", typ.Name))
+			example.WriteString("    return value\n")
+			example.WriteString("}")
+			break
+		}
+
 		// For interfaces, show how to implement them
 		example.WriteString(fmt.Sprintf("// Example implementation of %s\n", typ.Name))
 		example.WriteString(fmt.Sprintf("type My%s struct {\n", typ.Name))
@@ -900,6 +1406,7 @@ func (d *Discoverer) generateFunctionDeclaration(fn *doc.Func, funcDecl *ast.Fun
 	} else {
 		// Function
 		result.WriteString(fmt.Sprintf("func %s", fn.Name))
+		result.WriteString(d.formatTypeParams(funcDecl.Type.TypeParams))
 	}
 
 	// Parameters
@@ -952,11 +1459,13 @@ func (d *Discoverer) generateFunctionDeclaration(fn *doc.Func, funcDecl *ast.Fun
 	return result.String()
 }
 
-// generateStructDeclaration creates a clean struct declaration
+// generateStructDeclaration creates a clean struct declaration. Fields
+// excluded from the docs are replaced by a comment, like go doc does.
 func (d *Discoverer) generateStructDeclaration(name string, structType *ast.StructType) string {
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("type %s struct {\n", name))
 
+	filtered := false
 	for _, field := range structType.Fields.List {
 		fieldType := d.formatType(field.Type)
 		fieldTag := ""
@@ -966,13 +1475,22 @@ func (d *Discoverer) generateStructDeclaration(name string, structType *ast.Stru
 
 		if len(field.Names) > 0 {
 			for _, fieldName := range field.Names {
+				if !d.documented(fieldName.Name) {
+					filtered = true
+					continue
+				}
 				result.WriteString(fmt.Sprintf("    %s %s%s\n", fieldName.Name, fieldType, fieldTag))
 			}
+		} else if !d.documented(typeBaseName(field.Type)) {
+			filtered = true
 		} else {
 			// Embedded field
 			result.WriteString(fmt.Sprintf("    %s%s\n", fieldType, fieldTag))
 		}
 	}
+	if filtered {
+		result.WriteString("    // contains filtered or unexported fields\n")
+	}
 
 	result.WriteString("}")
 	return result.String()
@@ -1116,7 +1634,7 @@ func (d *Discoverer) extractManualComments(file *ast.File, pos token.Pos) string
 	filePos := fileSet.Position(pos)
 
 	// Read the file content
-	content, err := os.ReadFile(filePos.Filename)
+	content, err := d.files.ReadFile(filePos.Filename)
 	if err != nil {
 		return ""
 	}
@@ -1222,6 +1740,28 @@ func (d *Discoverer) extractMainDescription(doc string) string {
 	return strings.TrimSpace(strings.Join(mainLines, " "))
 }
 
+// mainDocText returns the doc comment text before the Parameters and Returns
+// sections, keeping its line structure for go/doc/comment
+func mainDocText(doc string) string {
+	lines := strings.Split(doc, "\n")
+	for i, line := range lines {
+		lower := strings.ToLower(strings.TrimSpace(line))
+		if strings.HasPrefix(lower, "parameters:") || strings.HasPrefix(lower, "returns:") {
+			return strings.TrimSpace(strings.Join(lines[:i], "\n"))
+		}
+	}
+	return strings.TrimSpace(doc)
+}
+
+// fieldDocText returns the doc comment of a field, or its line comment if it
+// has none
+func fieldDocText(field *ast.Field) string {
+	if field.Doc != nil {
+		return field.Doc.Text()
+	}
+	return field.Comment.Text()
+}
+
 // extractTypeDoc extracts documentation from AST comments for types
 func (d *Discoverer) extractTypeDoc(commentGroup *ast.CommentGroup) string {
 	if commentGroup == nil {
@@ -0,0 +1,104 @@
+package discovery
+
+import (
+	"bytes"
+	"go/ast"
+	"go/constant"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// evaluator evaluates constant expressions of a package's syntax, e.g. the
+// names and defaults of flags
+type evaluator struct {
+	fileSet  *token.FileSet
+	imports  map[string]string // Import paths by package name
+	typesPkg *types.Package
+}
+
+// defaultValue formats the default of a flag as a Go literal. Zero values
+// are left empty, like flag and cobra omit them from their help output.
+func (e *evaluator) defaultValue(expr ast.Expr) string {
+	value := e.exprString(expr)
+	if constant := e.constant(expr); constant != nil {
+		value = constant.ExactString()
+	}
+
+	switch value {
+	case `""`, "``", "false", "0", "0.0", "nil":
+		return ""
+	}
+	if strings.HasSuffix(value, "{}") {
+		return ""
+	}
+	return value
+}
+
+// stringValue evaluates a constant string expression
+func (e *evaluator) stringValue(expr ast.Expr) (string, bool) {
+	if value := e.constant(expr); value != nil && value.Kind() == constant.String {
+		return constant.StringVal(value), true
+	}
+	return "", false
+}
+
+// constant evaluates literals, constants of the package and of imported
+// packages, and + concatenations of them
+func (e *evaluator) constant(expr ast.Expr) constant.Value {
+	switch expr := unparen(expr).(type) {
+	case *ast.BasicLit:
+		value := constant.MakeFromLiteral(expr.Value, expr.Kind, 0)
+		if value.Kind() == constant.Unknown {
+			return nil
+		}
+		return value
+	case *ast.Ident:
+		if c, ok := lookupObject(e.typesPkg, expr.Name).(*types.Const); ok {
+			return c.Val()
+		}
+	case *ast.SelectorExpr:
+		pkg, ok := expr.X.(*ast.Ident)
+		if !ok || e.typesPkg == nil {
+			return nil
+		}
+		for _, imported := range e.typesPkg.Imports() {
+			if imported.Path() == e.imports[pkg.Name] {
+				if c, ok := imported.Scope().Lookup(expr.Sel.Name).(*types.Const); ok {
+					return c.Val()
+				}
+			}
+		}
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return nil
+		}
+		x, y := e.constant(expr.X), e.constant(expr.Y)
+		if x == nil || y == nil || x.Kind() != y.Kind() {
+			return nil
+		}
+		return constant.BinaryOp(x, token.ADD, y)
+	}
+	return nil
+}
+
+// exprString prints an expression on a single line
+func (e *evaluator) exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, e.fileSet, expr); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// unparen strips parentheses from an expression
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = paren.X
+	}
+}
@@ -0,0 +1,259 @@
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// selectExampleFiles returns the _test.go files of dir that belong to the
+// package pkgName or its external test package pkgName_test and build on any
+// configured platform. Files already selected for the package are skipped.
+func (d *Discoverer) selectExampleFiles(dir, pkgName string, selected []*sourceFile) ([]*sourceFile, error) {
+	entries, err := d.files.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	skip := make(map[string]bool)
+	for _, file := range selected {
+		skip[file.path] = true
+	}
+
+	var files []*sourceFile
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		if entry.IsDir() || !strings.HasSuffix(name, "_test.go") || skip[path] {
+			continue
+		}
+
+		var platforms []string
+		for _, platform := range d.platforms {
+			if match, err := platform.context.MatchFile(dir, name); err == nil && match {
+				platforms = append(platforms, platform.name)
+			}
+		}
+		if len(platforms) == 0 {
+			continue
+		}
+
+		clause, err := d.parseFile(path, parser.PackageClauseOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse package clause of %s: %w", path, err)
+		}
+		if clause.Name.Name != pkgName && clause.Name.Name != pkgName+"_test" {
+			continue
+		}
+
+		files = append(files, &sourceFile{path: path, platforms: platforms})
+	}
+
+	return files, nil
+}
+
+// extractExamples extracts the example functions of the test files, those
+// documented with the package under include_tests and the others, in file
+// order
+func (d *Discoverer) extractExamples(astPkg *ast.Package, testFiles []*sourceFile) ([]*Example, error) {
+	files := make(map[string]*ast.File, len(testFiles))
+	for path, file := range astPkg.Files {
+		if strings.HasSuffix(path, "_test.go") {
+			files[path] = file
+		}
+	}
+	for _, file := range testFiles {
+		parsed, err := d.parseFile(file.path, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.path, err)
+		}
+		files[file.path] = parsed
+	}
+
+	var examples []*Example
+	for _, file := range sortedFiles(files) {
+		for _, example := range doc.Examples(file) {
+			_, wholeFile := example.Code.(*ast.File)
+			examples = append(examples, &Example{
+				Name:        example.Name,
+				Doc:         example.Doc,
+				Code:        d.formatExampleCode(example),
+				Output:      example.Output,
+				EmptyOutput: example.EmptyOutput,
+				Unordered:   example.Unordered,
+				WholeFile:   wholeFile,
+			})
+		}
+	}
+
+	return examples, nil
+}
+
+// exampleOutputPattern matches the comment declaring the expected output of
+// an example, as go test recognizes it
+var exampleOutputPattern = regexp.MustCompile(`(?i)^[[:space:]]*(unordered )?output:`)
+
+// formatExampleCode prints the body of an example with its comments. Block
+// bodies are unwrapped and dedented so the code reads as a snippet, and their
+// output comment is left out since the output is rendered on its own.
+// Whole-file examples are printed as they are.
+func (d *Discoverer) formatExampleCode(example *doc.Example) string {
+	block, isBlock := example.Code.(*ast.BlockStmt)
+
+	// Like go test, only the last comment of the body declares the output.
+	// Comments hold all comments of the file.
+	comments := example.Comments
+	if isBlock {
+		comments = nil
+		for _, group := range example.Comments {
+			if group.Pos() > block.Pos() && group.End() < block.End() {
+				comments = append(comments, group)
+			}
+		}
+		if n := len(comments); n > 0 && exampleOutputPattern.MatchString(comments[n-1].Text()) {
+			comments = comments[:n-1]
+		}
+	}
+
+	var buf bytes.Buffer
+	node := &printer.CommentedNode{Node: example.Code, Comments: comments}
+	if err := (&printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}).Fprint(&buf, d.fileSet, node); err != nil {
+		return ""
+	}
+
+	code := strings.TrimSpace(buf.String())
+	if isBlock {
+		code = strings.TrimSuffix(strings.TrimPrefix(code, "{"), "}")
+		lines := strings.Split(strings.Trim(code, "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(line, "\t")
+		}
+		code = strings.Join(lines, "\n")
+	}
+
+	return code
+}
+
+// associateExamples attaches examples to the package, functions, types and
+// methods they are named after, following the naming convention of go test:
+// Example, ExampleF, ExampleT and ExampleT_M, each optionally followed by a
+// suffix starting with a lowercase letter. Like go doc, examples naming no
+// documented symbol are dropped.
+func associateExamples(pkgInfo *PackageInfo, examples []*Example) {
+	targets := map[string]*[]*Example{"": &pkgInfo.Examples}
+	symbols := map[string]string{"": ""}
+	for _, fn := range pkgInfo.Functions {
+		targets[fn.Name], symbols[fn.Name] = &fn.Examples, fn.Name
+	}
+	for _, typ := range pkgInfo.Types {
+		targets[typ.Name], symbols[typ.Name] = &typ.Examples, typ.Name
+		for _, fn := range typ.Funcs {
+			targets[fn.Name], symbols[fn.Name] = &fn.Examples, fn.Name
+		}
+		for _, method := range typ.Methods {
+			id := typ.Name + "_" + method.Name
+			targets[id], symbols[id] = &method.Examples, typ.Name+"."+method.Name
+		}
+	}
+
+	var associated []*Example
+	for _, example := range examples {
+		for i := len(example.Name); i >= 0; i = strings.LastIndexByte(example.Name[:i], '_') {
+			prefix, suffix, ok := splitExampleName(example.Name, i)
+			if !ok || targets[prefix] == nil {
+				continue
+			}
+
+			example.Symbol, example.Suffix = symbols[prefix], suffix
+			if prefix != "" {
+				*targets[prefix] = append(*targets[prefix], example)
+			}
+			associated = append(associated, example)
+			break
+		}
+	}
+
+	// PackageInfo.Examples lists all examples, package examples first
+	sort.SliceStable(associated, func(i, j int) bool {
+		return associated[i].Symbol == "" && associated[j].Symbol != ""
+	})
+	pkgInfo.Examples = associated
+
+	for prefix, exs := range targets {
+		if prefix == "" {
+			continue
+		}
+		sort.SliceStable(*exs, func(i, j int) bool {
+			return (*exs)[i].Suffix < (*exs)[j].Suffix
+		})
+	}
+}
+
+// splitExampleName splits an example name at index i into the symbol it
+// names and its suffix
+func splitExampleName(name string, i int) (prefix, suffix string, ok bool) {
+	if i == len(name) {
+		return name, "", true
+	}
+	if i == len(name)-1 {
+		return "", "", false
+	}
+	prefix, suffix = name[:i], name[i+1:]
+	return prefix, suffix, isExampleSuffix(suffix)
+}
+
+// isExampleSuffix reports whether s is a valid example suffix, which starts
+// with a lowercase letter
+func isExampleSuffix(s string) bool {
+	r, size := utf8.DecodeRuneInString(s)
+	return size > 0 && unicode.IsLower(r)
+}
+
+// PackageExamples returns the examples of the package as a whole
+func (pkg *PackageInfo) PackageExamples() []*Example {
+	var examples []*Example
+	for _, example := range pkg.Examples {
+		if example.Symbol == "" {
+			examples = append(examples, example)
+		}
+	}
+	return examples
+}
+
+// Title names an example for headings, e.g. "Buffer.Write (basic)", or
+// "Package" for package examples
+func (example *Example) Title() string {
+	title := example.Symbol
+	if title == "" {
+		title = "Package"
+	}
+	if example.Suffix != "" {
+		title += " (" + example.Suffix + ")"
+	}
+	return title
+}
+
+// Slug returns the file name stem of the example's page, e.g.
+// "buffer-write-basic"
+func (example *Example) Slug() string {
+	name := example.Name
+	if name == "" || name[0] == '_' {
+		name = "package" + name
+	}
+	return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
+
+// Anchor returns the heading anchor of the example on its package's examples
+// page
+func (example *Example) Anchor() string {
+	return headingAnchor(example.Title())
+}
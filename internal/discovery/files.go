@@ -0,0 +1,29 @@
+package discovery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"io/fs"
+
+	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/projectfs"
+)
+
+// NewFromFS creates a package discoverer reading the project from fsys
+// instead of the file system, e.g. from a git revision. fsys is rooted at the
+// project directory, and projectPath still names that directory: the paths of
+// the discovered packages and files are the ones they would have on disk.
+// Module versions come from the tags of the work tree and are left empty.
+func NewFromFS(cfg *config.Config, projectPath string, fsys fs.FS) *Discoverer {
+	return newDiscoverer(cfg, projectPath, projectfs.New(projectPath, fsys))
+}
+
+// parseFile parses a file of the project
+func (d *Discoverer) parseFile(path string, mode parser.Mode) (*ast.File, error) {
+	src, err := d.files.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return parser.ParseFile(d.fileSet, path, src, mode)
+}
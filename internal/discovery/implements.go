@@ -0,0 +1,224 @@
+package discovery
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// Implementation relates a type to an interface it satisfies. On an interface
+// it names an implementing type, on a concrete type it names the interface.
+type Implementation struct {
+	Name       string
+	Package    string // Package name, empty for predeclared interfaces such as error
+	ImportPath string
+	Pointer    bool // Only the pointer type *T satisfies the interface
+}
+
+// QualifiedName returns the package-qualified name, e.g. "io.Reader"
+func (impl *Implementation) QualifiedName() string {
+	if impl.Package == "" {
+		return impl.Name
+	}
+	return impl.Package + "." + impl.Name
+}
+
+// wellKnownInterface is a standard library interface matched against every
+// concrete type, described by the method keys of its method set
+type wellKnownInterface struct {
+	pkg        string
+	importPath string
+	name       string
+	methods    []string
+}
+
+// wellKnownInterfaces lists the standard library interfaces reported on
+// concrete types in addition to the interfaces of the project
+var wellKnownInterfaces = []*wellKnownInterface{
+	{"", "", "error", []string{"Error() string"}},
+	{"fmt", "fmt", "Stringer", []string{"String() string"}},
+	{"fmt", "fmt", "GoStringer", []string{"GoString() string"}},
+	{"io", "io", "Reader", []string{"Read([]byte) (int, error)"}},
+	{"io", "io", "Writer", []string{"Write([]byte) (int, error)"}},
+	{"io", "io", "Closer", []string{"Close() error"}},
+	{"io", "io", "ReadWriter", []string{"Read([]byte) (int, error)", "Write([]byte) (int, error)"}},
+	{"io", "io", "ReadCloser", []string{"Close() error", "Read([]byte) (int, error)"}},
+	{"io", "io", "WriteCloser", []string{"Close() error", "Write([]byte) (int, error)"}},
+	{"io", "io", "ReaderFrom", []string{"ReadFrom(io.Reader) (int64, error)"}},
+	{"io", "io", "WriterTo", []string{"WriteTo(io.Writer) (int64, error)"}},
+	{"sort", "sort", "Interface", []string{"Len() int", "Less(int, int) bool", "Swap(int, int)"}},
+	{"encoding", "encoding", "TextMarshaler", []string{"MarshalText() ([]byte, error)"}},
+	{"encoding", "encoding", "TextUnmarshaler", []string{"UnmarshalText([]byte) error"}},
+	{"json", "encoding/json", "Marshaler", []string{"MarshalJSON() ([]byte, error)"}},
+	{"json", "encoding/json", "Unmarshaler", []string{"UnmarshalJSON([]byte) error"}},
+	{"http", "net/http", "Handler", []string{"ServeHTTP(net/http.ResponseWriter, *net/http.Request)"}},
+}
+
+// resolveMethodSets records the method sets of a type for implementation
+// matching. Generic types are skipped, their method signatures mention type
+// parameters that can't be compared across declarations.
+func (d *Discoverer) resolveMethodSets(enhanced *EnhancedType) {
+	typeName, ok := enhanced.Object.(*types.TypeName)
+	if !ok || typeName.IsAlias() || len(enhanced.TypeParams) > 0 {
+		return
+	}
+
+	switch enhanced.TypeKind {
+	case "interface":
+		enhanced.MethodSet = methodSetKeys(typeName.Type())
+	case "constraint":
+	default:
+		enhanced.MethodSet = methodSetKeys(typeName.Type())
+		enhanced.PointerMethodSet = methodSetKeys(types.NewPointer(typeName.Type()))
+	}
+}
+
+// linkImplementations matches the method sets of all discovered types against
+// the interfaces of the project and the well-known standard library
+// interfaces, filling in Implements and ImplementedBy
+func linkImplementations(packages []*PackageInfo) {
+	type iface struct {
+		impl    *Implementation
+		typ     *EnhancedType // nil for well-known interfaces
+		methods []string
+	}
+
+	var ifaces []*iface
+	for _, known := range wellKnownInterfaces {
+		ifaces = append(ifaces, &iface{
+			impl:    &Implementation{Name: known.name, Package: known.pkg, ImportPath: known.importPath},
+			methods: known.methods,
+		})
+	}
+	for _, pkg := range packages {
+		for _, typ := range pkg.Types {
+			if typ.TypeKind == "interface" && len(typ.MethodSet) > 0 {
+				ifaces = append(ifaces, &iface{
+					impl:    &Implementation{Name: typ.Name, Package: pkg.Name, ImportPath: pkg.ImportPath},
+					typ:     typ,
+					methods: typ.MethodSet,
+				})
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		for _, typ := range pkg.Types {
+			typ.Implements, typ.ImplementedBy = nil, nil
+		}
+	}
+
+	for _, pkg := range packages {
+		for _, typ := range pkg.Types {
+			if typ.TypeKind == "interface" || typ.TypeKind == "constraint" || typ.TypeKind == "alias" {
+				continue
+			}
+
+			for _, candidate := range ifaces {
+				pointer := false
+				switch {
+				case containsAll(typ.MethodSet, candidate.methods):
+				case containsAll(typ.PointerMethodSet, candidate.methods):
+					pointer = true
+				default:
+					continue
+				}
+
+				implements := *candidate.impl
+				implements.Pointer = pointer
+				typ.Implements = append(typ.Implements, &implements)
+
+				if candidate.typ != nil {
+					candidate.typ.ImplementedBy = append(candidate.typ.ImplementedBy, &Implementation{
+						Name:       typ.Name,
+						Package:    pkg.Name,
+						ImportPath: pkg.ImportPath,
+						Pointer:    pointer,
+					})
+				}
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		for _, typ := range pkg.Types {
+			sortImplementations(typ.Implements)
+			sortImplementations(typ.ImplementedBy)
+		}
+	}
+}
+
+// sortImplementations orders implementations by import path and name
+func sortImplementations(impls []*Implementation) {
+	sort.Slice(impls, func(i, j int) bool {
+		if impls[i].ImportPath != impls[j].ImportPath {
+			return impls[i].ImportPath < impls[j].ImportPath
+		}
+		return impls[i].Name < impls[j].Name
+	})
+}
+
+// containsAll reports whether the sorted method set contains every method
+func containsAll(methodSet, methods []string) bool {
+	if len(methodSet) == 0 {
+		return false
+	}
+	for _, method := range methods {
+		i := sort.SearchStrings(methodSet, method)
+		if i == len(methodSet) || methodSet[i] != method {
+			return false
+		}
+	}
+	return true
+}
+
+// methodSetKeys returns the sorted method keys of the method set of t
+func methodSetKeys(t types.Type) []string {
+	methodSet := types.NewMethodSet(t)
+	keys := make([]string, 0, methodSet.Len())
+	for i := 0; i < methodSet.Len(); i++ {
+		if fn, ok := methodSet.At(i).Obj().(*types.Func); ok {
+			keys = append(keys, methodKey(fn))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// methodKey identifies a method by its name and signature without parameter
+// names, with types qualified by import path, e.g.
+// "Read([]byte) (int, error)". Unexported methods are qualified by their
+// package, since only that package can implement them.
+func methodKey(fn *types.Func) string {
+	sig := fn.Type().(*types.Signature)
+
+	var key strings.Builder
+	key.WriteString(fn.Id())
+	key.WriteString(tupleKey(sig.Params(), sig.Variadic()))
+
+	switch results := sig.Results(); results.Len() {
+	case 0:
+	case 1:
+		key.WriteString(" " + types.TypeString(results.At(0).Type(), nil))
+	default:
+		key.WriteString(" " + tupleKey(results, false))
+	}
+
+	return key.String()
+}
+
+// tupleKey formats the types of a parameter or result list
+func tupleKey(tuple *types.Tuple, variadic bool) string {
+	parts := make([]string, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		typ := tuple.At(i).Type()
+		if variadic && i == tuple.Len()-1 {
+			if slice, ok := typ.(*types.Slice); ok {
+				parts[i] = "..." + types.TypeString(slice.Elem(), nil)
+				continue
+			}
+		}
+		parts[i] = types.TypeString(typ, nil)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,214 @@
+package discovery
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Symbol is a documented identifier and the generated page describing it
+type Symbol struct {
+	ImportPath string
+	Name       string // Identifier, "Type.Method" for methods
+	Kind       string // func, type, method, const or var
+	Page       string // Generated page relative to the output directory
+	Anchor     string // Heading anchor of the symbol on its page
+}
+
+// SymbolIndex maps the exported identifiers of all discovered packages to
+// their generated API reference pages
+type SymbolIndex struct {
+	symbols  map[string]*Symbol
+	packages map[string]*PackageInfo
+}
+
+// NewSymbolIndex indexes the functions, types, methods, constants and
+// variables of the given packages. Unexported symbols are only indexed if
+// unexported is set.
+func NewSymbolIndex(packages []*PackageInfo, unexported bool) *SymbolIndex {
+	index := &SymbolIndex{
+		symbols:  make(map[string]*Symbol),
+		packages: make(map[string]*PackageInfo),
+	}
+
+	for _, pkg := range packages {
+		index.packages[pkg.ImportPath] = pkg
+		page := APIReferencePage(pkg)
+
+		add := func(name, kind, heading string) {
+			for _, part := range strings.Split(name, ".") {
+				if !unexported && !token.IsExported(part) {
+					return
+				}
+			}
+			index.symbols[pkg.ImportPath+"."+name] = &Symbol{
+				ImportPath: pkg.ImportPath,
+				Name:       name,
+				Kind:       kind,
+				Page:       page,
+				Anchor:     headingAnchor(heading),
+			}
+		}
+		addValues := func(values []*EnhancedValue, kind, heading string) {
+			for _, value := range values {
+				// Package-level declarations are headed by all their names
+				valueHeading := heading
+				if valueHeading == "" {
+					valueHeading = strings.Join(value.Names, ", ")
+				}
+				for _, name := range value.Names {
+					add(name, kind, valueHeading)
+				}
+			}
+		}
+
+		// Headings use the bare identifier, except for methods, which are
+		// headed by their receiver type so "Square.Area" doesn't share the
+		// anchor of a function "Area" or of the methods of other types
+		for _, fn := range pkg.Functions {
+			add(fn.Name, "func", fn.Name)
+		}
+		for _, typ := range pkg.Types {
+			add(typ.Name, "type", typ.Name)
+			for _, fn := range typ.Funcs {
+				add(fn.Name, "func", fn.Name)
+			}
+			for _, method := range typ.Methods {
+				add(typ.Name+"."+method.Name, "method", typ.Name+"."+method.Name)
+			}
+			addValues(typ.Consts, "const", typ.Name)
+			addValues(typ.Vars, "var", typ.Name)
+		}
+		addValues(pkg.Constants, "const", "")
+		addValues(pkg.Variables, "var", "")
+	}
+
+	return index
+}
+
+// Lookup returns the symbol with the given name in the package with the
+// given import path, or nil if it isn't documented
+func (index *SymbolIndex) Lookup(importPath, name string) *Symbol {
+	if index == nil {
+		return nil
+	}
+	return index.symbols[importPath+"."+name]
+}
+
+// Package returns the discovered package with the given import path
+func (index *SymbolIndex) Package(importPath string) *PackageInfo {
+	if index == nil {
+		return nil
+	}
+	return index.packages[importPath]
+}
+
+// APIReferencePage returns the API reference page of a package relative to
+// the output directory
+func APIReferencePage(pkg *PackageInfo) string {
+	return path.Join("api-reference", pkg.Slug+".md")
+}
+
+// assignSlugs names the pages of every package after its directory relative
+// to the project, e.g. "internal-cli", so packages sharing a name, in one
+// module or several, get pages of their own. The package at the project root
+// is named after the last element of its import path.
+func (d *Discoverer) assignSlugs(packages []*PackageInfo) {
+	used := make(map[string]bool)
+	for _, pkg := range packages {
+		slug := path.Base(pkg.ImportPath)
+		if rel, err := filepath.Rel(d.projectPath, pkg.Path); err == nil && rel != "." && fs.ValidPath(filepath.ToSlash(rel)) {
+			slug = strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+		}
+		if slug == "" || slug == "." {
+			slug = pkg.Name
+		}
+
+		// The root package may share the name of a directory
+		for base, n := slug, 2; used[slug]; n++ {
+			slug = base + "-" + strconv.Itoa(n)
+		}
+		used[slug] = true
+		pkg.Slug = slug
+	}
+}
+
+var anchorInvalidChars = regexp.MustCompile(`[^a-z0-9_\- ]`)
+
+// headingAnchor returns the anchor GitHub and GitBook generate for a heading
+func headingAnchor(heading string) string {
+	anchor := anchorInvalidChars.ReplaceAllString(strings.ToLower(heading), "")
+	return strings.ReplaceAll(anchor, " ", "-")
+}
+
+// IsStandardLibrary reports whether an import path belongs to the standard
+// library, whose first path element never contains a dot
+func IsStandardLibrary(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// packageImports maps the names under which the files of a package refer to
+// their imports to the import paths. Named imports use their alias, other
+// imports the package name from type information, or a guess based on the
+// import path when type-checking failed.
+func packageImports(astPkg *ast.Package, typesPkg *types.Package) map[string]string {
+	names := make(map[string]string)
+	if typesPkg != nil {
+		for _, imported := range typesPkg.Imports() {
+			names[imported.Path()] = imported.Name()
+		}
+	}
+
+	imports := make(map[string]string)
+	for _, file := range sortedFiles(astPkg.Files) {
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+
+			name := names[importPath]
+			if name == "" {
+				name = guessPackageName(importPath)
+			}
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			if name == "_" || name == "." {
+				continue
+			}
+
+			if _, exists := imports[name]; !exists {
+				imports[name] = importPath
+			}
+		}
+	}
+
+	return imports
+}
+
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// guessPackageName derives the conventional package name from an import
+// path, e.g. "yaml" for "gopkg.in/yaml.v3" and "cobra" for
+// "github.com/spf13/cobra/v2"
+func guessPackageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if majorVersionSuffix.MatchString(name) && len(elems) > 1 {
+		name = elems[len(elems)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, ".go")
+	return strings.ReplaceAll(name, "-", "_")
+}
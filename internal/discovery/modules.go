@@ -0,0 +1,154 @@
+package discovery
+
+import (
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kolosys/proton/internal/modfile"
+)
+
+// Module describes a Go module of the project
+type Module struct {
+	Path      string         // Module path declared in go.mod
+	Dir       string         // Absolute module root directory
+	RelDir    string         // Module root relative to the project, "." for the root
+	Version   string         // Latest release tag of the module, empty if untagged
+	GoVersion string         // Go version declared in go.mod
+	Slug      string         // File-system friendly identifier used for generated pages
+	Packages  []*PackageInfo // Packages discovered in the module
+}
+
+// detectModules finds the modules of the project. A go.work file at the
+// project root defines the modules through its use directives, otherwise the
+// root go.mod and every nested go.mod are used.
+func (d *Discoverer) detectModules() []*Module {
+	var dirs []string
+
+	if data, err := d.files.ReadFile(filepath.Join(d.projectPath, "go.work")); err == nil {
+		for _, dir := range modfile.WorkUses(data) {
+			dirs = append(dirs, filepath.Join(d.projectPath, filepath.FromSlash(dir)))
+		}
+	} else {
+		d.files.WalkDir(d.projectPath, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if entry.IsDir() {
+				name := entry.Name()
+				if path != d.projectPath && (name == "vendor" || name == "testdata" ||
+					strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.Name() == "go.mod" {
+				dirs = append(dirs, filepath.Dir(path))
+			}
+			return nil
+		})
+	}
+
+	var modules []*Module
+	for _, dir := range dirs {
+		data, err := d.files.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			continue
+		}
+
+		module := &Module{
+			Path:      modfile.ModulePath(data),
+			Dir:       dir,
+			GoVersion: modfile.GoVersion(data),
+		}
+		if module.Path == "" {
+			continue
+		}
+
+		module.RelDir = "."
+		if relDir, err := filepath.Rel(d.projectPath, dir); err == nil {
+			module.RelDir = filepath.ToSlash(relDir)
+		}
+		module.Slug = moduleSlug(module)
+		// Tags describe the work tree, not a revision read through an fs.FS
+		if d.files.WorkTree() {
+			module.Version = d.moduleVersion(module)
+		}
+
+		modules = append(modules, module)
+	}
+
+	// Projects without a go.mod still document their packages under the configured import path
+	if len(modules) == 0 {
+		module := &Module{
+			Path:   d.config.Repository.ImportPath,
+			Dir:    d.projectPath,
+			RelDir: ".",
+		}
+		module.Slug = moduleSlug(module)
+		modules = append(modules, module)
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].RelDir < modules[j].RelDir
+	})
+
+	return modules
+}
+
+// moduleFor returns the module containing the given directory, which is the
+// one with the deepest root directory
+func (d *Discoverer) moduleFor(dir string) *Module {
+	var best *Module
+	for _, module := range d.modules {
+		rel, err := filepath.Rel(module.Dir, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if best == nil || len(module.Dir) > len(best.Dir) {
+			best = module
+		}
+	}
+	return best
+}
+
+// moduleVersion determines the latest release tag of a module. Nested modules
+// follow the Go convention of tags prefixed with the module directory, e.g.
+// "tools/v1.2.0".
+func (d *Discoverer) moduleVersion(module *Module) string {
+	prefix := ""
+	if module.RelDir != "." {
+		prefix = module.RelDir + "/"
+	}
+
+	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0", "--match", prefix+"v[0-9]*")
+	cmd.Dir = module.Dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), prefix)
+}
+
+// moduleSlug derives the page identifier of a module from its directory
+func moduleSlug(module *Module) string {
+	if module.RelDir == "." {
+		return filepath.Base(module.Path)
+	}
+	return strings.ReplaceAll(module.RelDir, "/", "-")
+}
+
+// SymbolIndex returns the index of the documented symbols of all discovered
+// packages. It is populated by DiscoverPackages.
+func (d *Discoverer) SymbolIndex() *SymbolIndex {
+	return d.index
+}
+
+// Modules returns the modules of the project with their discovered packages.
+// It is populated by DiscoverPackages.
+func (d *Discoverer) Modules() []*Module {
+	return d.modules
+}
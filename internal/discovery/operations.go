@@ -0,0 +1,545 @@
+package discovery
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// viperImportPath is the import path of the viper configuration library
+const viperImportPath = "github.com/spf13/viper"
+
+// OperationsFacts are the command line flags registered with the standard
+// flag package and the environment variables a package reads
+type OperationsFacts struct {
+	Flags   []*FlagDefinition
+	EnvVars []*EnvVar
+}
+
+// FlagDefinition is a flag registered with the flag package, e.g.
+// flag.String("addr", ":8080", "listen address")
+type FlagDefinition struct {
+	Name    string
+	FlagSet string // Name passed to flag.NewFlagSet, empty for flag.CommandLine
+	Type    string // Value type, e.g. "string" or "duration"; empty for custom values
+	Default string // Default value as Go literal, empty for zero values
+	Usage   string
+	Source  SourcePosition
+}
+
+// EnvVar is an environment variable read with os.Getenv or os.LookupEnv,
+// or bound to a viper configuration key
+type EnvVar struct {
+	Name    string
+	Type    string // Type of the value, "string" for os.Getenv
+	Default string // Fallback as Go literal, e.g. of cmp.Or or SetDefault, empty if unknown
+	Usage   string // Comment next to the call
+	Key     string // Configuration key the variable overrides, empty for os.Getenv
+	Sources []SourcePosition
+}
+
+// SourcePosition is a line of a source file
+type SourcePosition struct {
+	File string // Slash-separated path relative to the project root
+	Line int
+}
+
+// String formats the position as "file:line"
+func (p SourcePosition) String() string {
+	return p.File + ":" + strconv.Itoa(p.Line)
+}
+
+// operationsAnalyzer collects the operations facts of a package
+type operationsAnalyzer struct {
+	*evaluator
+	projectPath string
+	facts       *OperationsFacts
+
+	// Viper configuration: keys with defaults and the settings that turn
+	// them into environment variables
+	viperKeys    []*EnvVar
+	viperBound   []*EnvVar
+	envPrefix    string
+	replacer     []string // Pairs of strings.NewReplacer arguments
+	automaticEnv bool
+}
+
+// collectOperationsFacts analyzes the files of a package for flags of the
+// flag package, os.Getenv and os.LookupEnv calls and viper's environment
+// bindings. It returns nil for packages without any.
+func collectOperationsFacts(fileSet *token.FileSet, astPkg *ast.Package, projectPath string, imports map[string]string, typesPkg *types.Package) *OperationsFacts {
+	analyzer := &operationsAnalyzer{
+		evaluator:   &evaluator{fileSet: fileSet, imports: imports, typesPkg: typesPkg},
+		projectPath: projectPath,
+		facts:       &OperationsFacts{},
+	}
+
+	for _, file := range sortedFiles(astPkg.Files) {
+		if strings.HasSuffix(fileSet.Position(file.Pos()).Filename, "_test.go") {
+			continue
+		}
+		analyzer.file(file)
+	}
+
+	facts := analyzer.facts
+	if analyzer.automaticEnv {
+		for _, key := range analyzer.viperKeys {
+			key.Name = analyzer.viperEnvName(key.Key)
+			facts.EnvVars = append(facts.EnvVars, key)
+		}
+	}
+	for _, bound := range analyzer.viperBound {
+		if bound.Name == "" {
+			bound.Name = analyzer.viperEnvName(bound.Key)
+		}
+		facts.EnvVars = append(facts.EnvVars, bound)
+	}
+
+	if len(facts.Flags) == 0 && len(facts.EnvVars) == 0 {
+		return nil
+	}
+	return facts
+}
+
+// file records the flags and environment variables of a file
+func (a *operationsAnalyzer) file(file *ast.File) {
+	flagName, hasFlag := fileImportName(file, "flag")
+	osName, hasOS := fileImportName(file, "os")
+	cmpName, hasCmp := fileImportName(file, "cmp")
+	_, hasViper := fileImportName(file, viperImportPath)
+	if !hasFlag && !hasOS && !hasViper {
+		return
+	}
+
+	// Variables holding flag sets, by the name of the set
+	flagSets := make(map[string]string)
+	if hasFlag {
+		ast.Inspect(file, func(n ast.Node) bool {
+			var lhs, rhs []ast.Expr
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				lhs, rhs = n.Lhs, n.Rhs
+			case *ast.ValueSpec:
+				for _, name := range n.Names {
+					lhs = append(lhs, name)
+				}
+				rhs = n.Values
+			default:
+				return true
+			}
+			for i := range min(len(lhs), len(rhs)) {
+				ident, ok := lhs[i].(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if call, ok := rhs[i].(*ast.CallExpr); ok && isPackageCall(call, flagName, "NewFlagSet") && len(call.Args) > 0 {
+					flagSets[ident.Name], _ = a.stringValue(call.Args[0])
+				}
+			}
+			return true
+		})
+	}
+
+	// Fallbacks passed along with os.Getenv to cmp.Or
+	fallbacks := make(map[*ast.CallExpr]ast.Expr)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		method := selector.Sel.Name
+		receiver, _ := selector.X.(*ast.Ident)
+		var flagSet string
+		var isFlagSet bool
+		if receiver != nil {
+			flagSet, isFlagSet = flagSets[receiver.Name]
+		}
+
+		switch {
+		case hasCmp && isPackageCall(call, cmpName, "Or") && len(call.Args) > 1:
+			if getenv, ok := unparen(call.Args[0]).(*ast.CallExpr); ok {
+				fallbacks[getenv] = call.Args[len(call.Args)-1]
+			}
+
+		case hasOS && isPackageCall(call, osName, "Getenv", "LookupEnv") && len(call.Args) == 1:
+			name, ok := a.stringValue(call.Args[0])
+			if !ok {
+				return true
+			}
+			envVar := &EnvVar{
+				Name:    name,
+				Type:    "string",
+				Usage:   commentNear(a.fileSet, file, call.Pos()),
+				Sources: []SourcePosition{a.position(call.Pos())},
+			}
+			if fallback, ok := fallbacks[call]; ok {
+				envVar.Default = a.defaultValue(fallback)
+			}
+			a.facts.EnvVars = append(a.facts.EnvVars, envVar)
+
+		case hasFlag && receiver != nil && (receiver.Name == flagName || isFlagSet):
+			if flag := a.flag(method, call.Args); flag != nil {
+				flag.FlagSet = flagSet
+				flag.Source = a.position(call.Pos())
+				a.facts.Flags = append(a.facts.Flags, flag)
+			}
+
+		case hasViper:
+			a.viperCall(method, call, file)
+		}
+		return true
+	})
+}
+
+// viperCall records SetDefault, BindEnv and the environment settings of a
+// viper instance or the global viper
+func (a *operationsAnalyzer) viperCall(method string, call *ast.CallExpr, file *ast.File) {
+	switch method {
+	case "SetDefault":
+		if len(call.Args) != 2 {
+			return
+		}
+		if key, ok := a.stringValue(call.Args[0]); ok {
+			a.viperKeys = append(a.viperKeys, &EnvVar{
+				Key:     strings.ToLower(key),
+				Type:    a.valueType(call.Args[1]),
+				Default: defaultValue(a.fileSet, call.Args[1], a.typesPkg),
+				Usage:   lineComment(a.fileSet, file, call.Pos()),
+				Sources: []SourcePosition{a.position(call.Pos())},
+			})
+		}
+	case "BindEnv":
+		if len(call.Args) == 0 {
+			return
+		}
+		key, ok := a.stringValue(call.Args[0])
+		if !ok {
+			return
+		}
+		names := []string{""}
+		if len(call.Args) > 1 {
+			names = nil
+			for _, arg := range call.Args[1:] {
+				if name, ok := a.stringValue(arg); ok {
+					names = append(names, name)
+				}
+			}
+		}
+		for _, name := range names {
+			a.viperBound = append(a.viperBound, &EnvVar{
+				Name:    name,
+				Key:     strings.ToLower(key),
+				Usage:   commentNear(a.fileSet, file, call.Pos()),
+				Sources: []SourcePosition{a.position(call.Pos())},
+			})
+		}
+	case "SetEnvPrefix":
+		if len(call.Args) == 1 {
+			a.envPrefix, _ = a.stringValue(call.Args[0])
+		}
+	case "AutomaticEnv":
+		a.automaticEnv = true
+	case "SetEnvKeyReplacer":
+		if len(call.Args) != 1 {
+			return
+		}
+		replacer, ok := unparen(call.Args[0]).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		if selector, ok := replacer.Fun.(*ast.SelectorExpr); !ok || selector.Sel.Name != "NewReplacer" {
+			return
+		}
+		a.replacer = nil
+		for _, arg := range replacer.Args {
+			value, ok := a.stringValue(arg)
+			if !ok {
+				a.replacer = nil
+				return
+			}
+			a.replacer = append(a.replacer, value)
+		}
+	}
+}
+
+// viperEnvName returns the environment variable viper reads for a key: the
+// key uppercased behind the prefix, with the key replacer applied
+func (a *operationsAnalyzer) viperEnvName(key string) string {
+	name := key
+	if a.envPrefix != "" {
+		name = a.envPrefix + "_" + key
+	}
+	name = strings.ToUpper(name)
+	if len(a.replacer)%2 == 0 && len(a.replacer) > 0 {
+		name = strings.NewReplacer(a.replacer...).Replace(name)
+	}
+	return name
+}
+
+// flag parses a flag registration of the flag package such as
+// String("addr", ":8080", "usage") or DurationVar(&p, "timeout", 0, "usage"),
+// returning nil for other methods
+func (a *operationsAnalyzer) flag(method string, args []ast.Expr) *FlagDefinition {
+	var (
+		nameIndex, defaultIndex, usageIndex, argCount int
+		typ                                           string
+	)
+	switch method {
+	case "Func":
+		nameIndex, defaultIndex, usageIndex, argCount, typ = 0, -1, 1, 3, "string"
+	case "BoolFunc":
+		nameIndex, defaultIndex, usageIndex, argCount, typ = 0, -1, 1, 3, "bool"
+	case "Var":
+		nameIndex, defaultIndex, usageIndex, argCount = 1, -1, 2, 3
+	case "TextVar":
+		nameIndex, defaultIndex, usageIndex, argCount = 1, 2, 3, 4
+	default:
+		base, isVar := strings.CutSuffix(method, "Var")
+		if !stdFlagTypes[base] {
+			return nil
+		}
+		nameIndex, defaultIndex, usageIndex, argCount, typ = 0, 1, 2, 3, strings.ToLower(base)
+		if isVar {
+			nameIndex, defaultIndex, usageIndex, argCount = 1, 2, 3, 4
+		}
+	}
+	if len(args) != argCount {
+		return nil
+	}
+
+	name, ok := a.stringValue(args[nameIndex])
+	if !ok {
+		return nil
+	}
+
+	flag := &FlagDefinition{Name: name, Type: typ}
+	if defaultIndex >= 0 {
+		flag.Default = a.defaultValue(args[defaultIndex])
+	}
+	if usage, ok := a.stringValue(args[usageIndex]); ok {
+		flag.Usage = usage
+	} else {
+		flag.Usage = a.exprString(args[usageIndex])
+	}
+	return flag
+}
+
+// stdFlagTypes are the value types of the flag package's registration
+// functions, e.g. String and StringVar
+var stdFlagTypes = map[string]bool{
+	"Bool": true, "Duration": true, "Float64": true, "Int": true, "Int64": true,
+	"String": true, "Uint": true, "Uint64": true,
+}
+
+// valueType infers the type of a default value from literals and the
+// declared types of constants and variables
+func (a *operationsAnalyzer) valueType(expr ast.Expr) string {
+	switch expr := unparen(expr).(type) {
+	case *ast.BasicLit:
+		switch expr.Kind {
+		case token.INT:
+			return "int"
+		case token.FLOAT:
+			return "float64"
+		case token.STRING:
+			return "string"
+		}
+	case *ast.CompositeLit:
+		if expr.Type != nil {
+			return a.exprString(expr.Type)
+		}
+	case *ast.Ident:
+		if expr.Name == "true" || expr.Name == "false" {
+			return "bool"
+		}
+		if obj := lookupObject(a.typesPkg, expr.Name); obj != nil {
+			return types.TypeString(types.Default(obj.Type()), types.RelativeTo(a.typesPkg))
+		}
+	}
+	return ""
+}
+
+// position returns the source position relative to the project root
+func (a *operationsAnalyzer) position(pos token.Pos) SourcePosition {
+	position := a.fileSet.Position(pos)
+	file := position.Filename
+	if rel, err := filepath.Rel(a.projectPath, file); err == nil {
+		file = rel
+	}
+	return SourcePosition{File: filepath.ToSlash(file), Line: position.Line}
+}
+
+// commentNear returns the comment on the line of pos or on the line above
+// it, which usually describes the variable read there. Doc comments of
+// declarations describe the declaration instead.
+func commentNear(fileSet *token.FileSet, file *ast.File, pos token.Pos) string {
+	docs := make(map[*ast.CommentGroup]bool)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			docs[decl.Doc] = true
+		case *ast.GenDecl:
+			docs[decl.Doc] = true
+		}
+	}
+
+	line := fileSet.Position(pos).Line
+	for _, group := range file.Comments {
+		start, end := fileSet.Position(group.Pos()).Line, fileSet.Position(group.End()).Line
+		if !docs[group] && (start == line || end == line-1) {
+			return strings.Join(strings.Fields(group.Text()), " ")
+		}
+	}
+	return ""
+}
+
+// lineComment returns the comment trailing the line of pos. Comments above
+// SetDefault calls usually head a group of keys instead.
+func lineComment(fileSet *token.FileSet, file *ast.File, pos token.Pos) string {
+	line := fileSet.Position(pos).Line
+	for _, group := range file.Comments {
+		if fileSet.Position(group.Pos()).Line == line {
+			return strings.Join(strings.Fields(group.Text()), " ")
+		}
+	}
+	return ""
+}
+
+// isPackageCall reports whether call calls one of the functions of the
+// package imported as pkgName
+func isPackageCall(call *ast.CallExpr, pkgName string, functions ...string) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := selector.X.(*ast.Ident)
+	if !ok || pkg.Name != pkgName {
+		return false
+	}
+	for _, function := range functions {
+		if selector.Sel.Name == function {
+			return true
+		}
+	}
+	return false
+}
+
+// OperationsReference lists the flags and environment variables of the
+// binaries of the project
+type OperationsReference struct {
+	Binaries []*Binary
+}
+
+// Binary is a main package with the flags and environment variables read by
+// it and the discovered packages it imports
+type Binary struct {
+	Name    string // Name of the built binary, the last element of the import path
+	Package *PackageInfo
+	Flags   []*FlagDefinition
+	EnvVars []*EnvVar
+	Cobra   bool // The binary's packages define cobra commands, whose flags the CLI reference lists
+}
+
+// NewOperationsReference collects the flags and environment variables of
+// each main package and the discovered packages it imports, directly or
+// indirectly. Main packages reading neither are left out.
+func NewOperationsReference(packages []*PackageInfo) *OperationsReference {
+	byImportPath := make(map[string]*PackageInfo, len(packages))
+	for _, pkg := range packages {
+		byImportPath[pkg.ImportPath] = pkg
+	}
+
+	reference := &OperationsReference{}
+	for _, pkg := range packages {
+		if pkg.Name != "main" {
+			continue
+		}
+
+		binary := &Binary{Name: path.Base(pkg.ImportPath), Package: pkg}
+		envVars := make(map[string]*EnvVar)
+		flags := make(map[string]bool)
+
+		// Walk the imports breadth-first, so the main package's own
+		// definitions come first
+		visited := map[*PackageInfo]bool{pkg: true}
+		for queue := []*PackageInfo{pkg}; len(queue) > 0; queue = queue[1:] {
+			current := queue[0]
+			if current.Cobra != nil {
+				binary.Cobra = true
+			}
+			if facts := current.Operations; facts != nil {
+				for _, flag := range facts.Flags {
+					if id := flag.FlagSet + " " + flag.Name; !flags[id] {
+						flags[id] = true
+						binary.Flags = append(binary.Flags, flag)
+					}
+				}
+				for _, envVar := range facts.EnvVars {
+					merged, ok := envVars[envVar.Name]
+					if !ok {
+						merged = &EnvVar{Name: envVar.Name}
+						envVars[envVar.Name] = merged
+						binary.EnvVars = append(binary.EnvVars, merged)
+					}
+					mergeEnvVar(merged, envVar)
+				}
+			}
+
+			importPaths := make([]string, 0, len(current.Imports))
+			for _, importPath := range current.Imports {
+				importPaths = append(importPaths, importPath)
+			}
+			sort.Strings(importPaths)
+			for _, importPath := range importPaths {
+				if imported := byImportPath[importPath]; imported != nil && !visited[imported] {
+					visited[imported] = true
+					queue = append(queue, imported)
+				}
+			}
+		}
+
+		if len(binary.Flags) == 0 && len(binary.EnvVars) == 0 {
+			continue
+		}
+		sort.SliceStable(binary.Flags, func(i, j int) bool {
+			if binary.Flags[i].FlagSet != binary.Flags[j].FlagSet {
+				return binary.Flags[i].FlagSet < binary.Flags[j].FlagSet
+			}
+			return binary.Flags[i].Name < binary.Flags[j].Name
+		})
+		sort.SliceStable(binary.EnvVars, func(i, j int) bool {
+			return binary.EnvVars[i].Name < binary.EnvVars[j].Name
+		})
+		reference.Binaries = append(reference.Binaries, binary)
+	}
+
+	return reference
+}
+
+// mergeEnvVar adds the sources of a read of an environment variable to the
+// variable, filling in what is known from that read only
+func mergeEnvVar(merged, envVar *EnvVar) {
+	merged.Sources = append(merged.Sources, envVar.Sources...)
+	if merged.Type == "" {
+		merged.Type = envVar.Type
+	}
+	if merged.Default == "" {
+		merged.Default = envVar.Default
+	}
+	if merged.Usage == "" {
+		merged.Usage = envVar.Usage
+	}
+	if merged.Key == "" {
+		merged.Key = envVar.Key
+	}
+}
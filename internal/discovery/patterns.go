@@ -0,0 +1,198 @@
+package discovery
+
+import (
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PatternSet matches package paths against a list of include or exclude
+// patterns. Patterns follow Go package pattern semantics, where "..." matches
+// any string and a trailing "/..." also matches the directory itself, so
+// "./test/..." matches "test" and "test/unit" but not "internal/contest".
+// Globs are supported as well: "*" and "?" match within a path element and
+// "**" matches any number of elements. A leading "!" negates a pattern, and
+// later patterns take precedence over earlier ones.
+type PatternSet struct {
+	patterns []*pathPattern
+}
+
+// pathPattern is a single compiled pattern
+type pathPattern struct {
+	raw    string
+	negate bool
+	re     *regexp.Regexp
+}
+
+// PatternMatch reports the packages matched by a single configured pattern
+type PatternMatch struct {
+	Pattern  string
+	Exclude  bool
+	Packages []string
+}
+
+// NewPatternSet compiles the given patterns
+func NewPatternSet(patterns []string) *PatternSet {
+	set := &PatternSet{}
+	for _, raw := range patterns {
+		set.patterns = append(set.patterns, compilePathPattern(raw))
+	}
+	return set
+}
+
+// Match reports whether a package path relative to the project root, using
+// forward slashes and "." for the root package, is matched by the set
+func (s *PatternSet) Match(pkgPath string) bool {
+	pkgPath = normalizePackagePath(pkgPath)
+
+	matched := false
+	for _, pattern := range s.patterns {
+		if pattern.re.MatchString(pkgPath) {
+			matched = !pattern.negate
+		}
+	}
+	return matched
+}
+
+// Empty reports whether the set has no patterns
+func (s *PatternSet) Empty() bool {
+	return len(s.patterns) == 0
+}
+
+// compilePathPattern translates a package pattern into a regular expression
+func compilePathPattern(raw string) *pathPattern {
+	pattern := &pathPattern{raw: raw}
+
+	text := strings.TrimSpace(raw)
+	if rest, ok := strings.CutPrefix(text, "!"); ok {
+		pattern.negate = true
+		text = rest
+	}
+	text = normalizePackagePath(text)
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(text); {
+		switch {
+		case text[i:] == "/...":
+			// "dir/..." matches dir itself and everything below it
+			expr.WriteString("(/.*)?")
+			i += len("/...")
+		case strings.HasPrefix(text[i:], "..."):
+			expr.WriteString(".*")
+			i += len("...")
+		case strings.HasPrefix(text[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += len("**/")
+		case strings.HasPrefix(text[i:], "**"):
+			expr.WriteString(".*")
+			i += len("**")
+		case text[i] == '*':
+			expr.WriteString("[^/]*")
+			i++
+		case text[i] == '?':
+			expr.WriteString("[^/]")
+			i++
+		default:
+			expr.WriteString(regexp.QuoteMeta(text[i : i+1]))
+			i++
+		}
+	}
+	expr.WriteString("$")
+
+	pattern.re = regexp.MustCompile(expr.String())
+	return pattern
+}
+
+// normalizePackagePath converts a path or pattern into slash-separated form
+// relative to the project root, with "." for the root itself
+func normalizePackagePath(path string) string {
+	path = filepath.ToSlash(path)
+	for strings.HasPrefix(path, "./") {
+		path = strings.TrimPrefix(path, "./")
+	}
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+// shouldIncludePackage checks a package path against the include and exclude patterns
+func (d *Discoverer) shouldIncludePackage(pkgPath string) bool {
+	if !d.includes.Empty() && !d.includes.Match(pkgPath) {
+		return false
+	}
+	return !d.excludes.Match(pkgPath)
+}
+
+// MatchPatterns reports, for every configured include and exclude pattern,
+// the package directories it matches. Negated patterns report the packages
+// they take back.
+func (d *Discoverer) MatchPatterns() ([]*PatternMatch, error) {
+	pkgPaths, err := d.packageDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*PatternMatch
+	report := func(patterns []*pathPattern, exclude bool) {
+		for _, pattern := range patterns {
+			match := &PatternMatch{Pattern: pattern.raw, Exclude: exclude}
+			for _, pkgPath := range pkgPaths {
+				if pattern.re.MatchString(pkgPath) {
+					match.Packages = append(match.Packages, pkgPath)
+				}
+			}
+			matches = append(matches, match)
+		}
+	}
+	report(d.includes.patterns, false)
+	report(d.excludes.patterns, true)
+
+	return matches, nil
+}
+
+// packageDirs lists the directories of the project containing Go files,
+// relative to the project root
+func (d *Discoverer) packageDirs() ([]string, error) {
+	seen := make(map[string]bool)
+
+	err := d.files.WalkDir(d.projectPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != d.projectPath && (name == "vendor" || name == "testdata" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(entry.Name(), ".go") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(d.projectPath, filepath.Dir(path))
+		if err != nil {
+			return nil
+		}
+		seen[normalizePackagePath(relPath)] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pkgPaths := make([]string, 0, len(seen))
+	for pkgPath := range seen {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.Strings(pkgPaths)
+
+	return pkgPaths, nil
+}
@@ -0,0 +1,50 @@
+package discovery
+
+import "testing"
+
+func TestPatternSetMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{"empty set", nil, "internal/cli", false},
+		{"exact path", []string{"internal/cli"}, "internal/cli", true},
+		{"exact path is not a prefix", []string{"internal/cli"}, "internal/client", false},
+		{"dot slash prefix", []string{"./internal/cli"}, "internal/cli", true},
+		{"root package", []string{"."}, ".", true},
+		{"root pattern is not recursive", []string{"."}, "internal", false},
+		{"all packages", []string{"./..."}, ".", true},
+		{"all packages nested", []string{"./..."}, "internal/cli", true},
+
+		{"trailing ellipsis matches directory", []string{"./test/..."}, "test", true},
+		{"trailing ellipsis matches subdirectory", []string{"./test/..."}, "test/unit", true},
+		{"trailing ellipsis stops at segment boundary", []string{"./test/..."}, "internal/contest", false},
+		{"trailing ellipsis is anchored", []string{"./test/..."}, "internal/test", false},
+		{"trailing ellipsis is not a name prefix", []string{"./test/..."}, "testdata", false},
+		{"inner ellipsis", []string{"internal/.../util"}, "internal/a/b/util", true},
+		{"ellipsis within an element", []string{"cmd/proton..."}, "cmd/protonctl", true},
+
+		{"star within an element", []string{"internal/*"}, "internal/cli", true},
+		{"star stays within an element", []string{"internal/*"}, "internal/cli/flags", false},
+		{"question mark", []string{"v?"}, "v2", true},
+		{"double star prefix", []string{"**/test"}, "test", true},
+		{"double star prefix nested", []string{"**/test"}, "internal/a/test", true},
+		{"double star prefix matches whole elements", []string{"**/test"}, "internal/contest", false},
+		{"double star suffix", []string{"internal/**"}, "internal/cli/flags", true},
+
+		{"negation", []string{"./...", "!internal/..."}, "internal/cli", false},
+		{"negation leaves others", []string{"./...", "!internal/..."}, "cmd/proton", true},
+		{"later pattern wins", []string{"./...", "!internal/...", "internal/cli"}, "internal/cli", true},
+		{"negation alone matches nothing", []string{"!internal/..."}, "cmd/proton", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewPatternSet(tt.patterns).Match(tt.path); got != tt.want {
+				t.Errorf("NewPatternSet(%q).Match(%q) = %v, want %v", tt.patterns, tt.path, got, tt.want)
+			}
+		})
+	}
+}
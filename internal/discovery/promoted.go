@@ -0,0 +1,207 @@
+package discovery
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// PromotedMember is a method or struct field promoted to a type through an
+// embedded field
+type PromotedMember struct {
+	Name           string
+	Signature      string // Method signature without "func", e.g. "Read(p []byte) (n int, err error)"; field type for fields
+	Exported       bool
+	Pointer        bool   // Only in the method set of the pointer type *T
+	From           string // Type declaring the member, qualified by package name if declared elsewhere, e.g. "io.Reader"
+	FromName       string // Unqualified name of the declaring type
+	FromImportPath string
+	Via            string // Embedded fields leading to the member, e.g. "Base" or "Base.Logger"
+}
+
+// resolvePromoted records the methods and fields a type gains through its
+// embedded fields, or for interfaces through embedded interfaces. Generic
+// types are skipped like for method sets.
+func (d *Discoverer) resolvePromoted(enhanced *EnhancedType) {
+	typeName, ok := enhanced.Object.(*types.TypeName)
+	if !ok || typeName.IsAlias() || len(enhanced.TypeParams) > 0 {
+		return
+	}
+
+	qualifier := packageNameQualifier(typeName.Pkg())
+	if iface, ok := typeName.Type().Underlying().(*types.Interface); ok {
+		enhanced.PromotedMethods = d.embeddedInterfaceMethods(iface, qualifier)
+		return
+	}
+
+	typ := typeName.Type()
+	valueSet := types.NewMethodSet(typ)
+	pointerSet := types.NewMethodSet(types.NewPointer(typ))
+	for i := 0; i < pointerSet.Len(); i++ {
+		selection := pointerSet.At(i)
+		fn, ok := selection.Obj().(*types.Func)
+		if !ok || len(selection.Index()) < 2 || !d.documented(fn.Name()) {
+			continue
+		}
+
+		member := promotedMember(typ, selection.Index(), fn, qualifier)
+		member.Signature = methodSignature(fn, qualifier)
+		member.Pointer = valueSet.Lookup(fn.Pkg(), fn.Name()) == nil
+		enhanced.PromotedMethods = append(enhanced.PromotedMethods, member)
+	}
+
+	for _, name := range embeddedFieldNames(typ) {
+		obj, index, _ := types.LookupFieldOrMethod(typ, true, typeName.Pkg(), name)
+		field, ok := obj.(*types.Var)
+		if !ok || !field.IsField() || len(index) < 2 || !d.documented(name) {
+			continue
+		}
+
+		member := promotedMember(typ, index, field, qualifier)
+		member.Signature = types.TypeString(field.Type(), qualifier)
+		enhanced.PromotedFields = append(enhanced.PromotedFields, member)
+	}
+}
+
+// embeddedInterfaceMethods lists the methods an interface gains through the
+// interfaces it embeds
+func (d *Discoverer) embeddedInterfaceMethods(iface *types.Interface, qualifier types.Qualifier) []*PromotedMember {
+	explicit := make(map[*types.Func]bool)
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		explicit[iface.ExplicitMethod(i)] = true
+	}
+
+	var members []*PromotedMember
+	for i := 0; i < iface.NumMethods(); i++ {
+		fn := iface.Method(i)
+		if explicit[fn] || !d.documented(fn.Name()) {
+			continue
+		}
+
+		member := &PromotedMember{
+			Name:      fn.Name(),
+			Signature: methodSignature(fn, qualifier),
+			Exported:  fn.Exported(),
+		}
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			setPromotedFrom(member, recv.Type(), qualifier)
+		}
+		members = append(members, member)
+	}
+	return members
+}
+
+// promotedMember describes the member obj reached from typ through the
+// embedded fields of the index path
+func promotedMember(typ types.Type, index []int, obj types.Object, qualifier types.Qualifier) *PromotedMember {
+	member := &PromotedMember{
+		Name:     obj.Name(),
+		Exported: obj.Exported(),
+	}
+
+	var via []string
+	current := typ
+	for _, i := range index[:len(index)-1] {
+		structType, ok := derefType(current).Underlying().(*types.Struct)
+		if !ok {
+			break
+		}
+		field := structType.Field(i)
+		via = append(via, field.Name())
+		current = field.Type()
+	}
+	member.Via = strings.Join(via, ".")
+
+	// Methods of embedded interfaces are declared by the interface itself
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			current = recv.Type()
+		}
+	}
+	setPromotedFrom(member, current, qualifier)
+
+	return member
+}
+
+// setPromotedFrom records the type declaring a promoted member
+func setPromotedFrom(member *PromotedMember, typ types.Type, qualifier types.Qualifier) {
+	named, ok := derefType(typ).(*types.Named)
+	if !ok {
+		member.From = types.TypeString(derefType(typ), qualifier)
+		return
+	}
+
+	obj := named.Obj()
+	member.FromName = obj.Name()
+	member.From = obj.Name()
+	if obj.Pkg() != nil {
+		member.FromImportPath = obj.Pkg().Path()
+		if qualifier(obj.Pkg()) != "" {
+			member.From = obj.Pkg().Name() + "." + obj.Name()
+		}
+	}
+}
+
+// embeddedFieldNames returns the sorted names of all fields declared by the
+// structs embedded in typ, at any depth
+func embeddedFieldNames(typ types.Type) []string {
+	seen := make(map[string]bool)
+	visited := make(map[types.Type]bool)
+
+	var walk func(t types.Type, embedded bool)
+	walk = func(t types.Type, embedded bool) {
+		t = derefType(t)
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+
+		structType, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return
+		}
+		for i := 0; i < structType.NumFields(); i++ {
+			field := structType.Field(i)
+			if embedded {
+				seen[field.Name()] = true
+			}
+			if field.Embedded() {
+				walk(field.Type(), true)
+			}
+		}
+	}
+	walk(typ, false)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// methodSignature formats a method without the "func" keyword, e.g.
+// "Read(p []byte) (n int, err error)"
+func methodSignature(fn *types.Func, qualifier types.Qualifier) string {
+	sig := fn.Type().(*types.Signature)
+	return fn.Name() + strings.TrimPrefix(types.TypeString(sig, qualifier), "func")
+}
+
+// packageNameQualifier qualifies types of other packages by their package
+// name, as they are written in source
+func packageNameQualifier(pkg *types.Package) types.Qualifier {
+	return func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		return other.Name()
+	}
+}
+
+// derefType returns the element type of a pointer type, or t itself
+func derefType(t types.Type) types.Type {
+	if pointer, ok := t.(*types.Pointer); ok {
+		return pointer.Elem()
+	}
+	return t
+}
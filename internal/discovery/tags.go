@@ -0,0 +1,84 @@
+package discovery
+
+import (
+	"strconv"
+	"strings"
+)
+
+// StructTag is a single key of a struct field tag, e.g. `yaml:"name,omitempty"`
+type StructTag struct {
+	Key     string   // Tag key, e.g. "yaml"
+	Name    string   // First element of the value, e.g. "name"; "-" if the field is ignored
+	Options []string // Remaining comma-separated elements, e.g. "omitempty"
+}
+
+// HasOption reports whether the tag has the given option
+func (tag *StructTag) HasOption(option string) bool {
+	return containsString(tag.Options, option)
+}
+
+// LookupTag returns the tag of a field with the given key, or nil if the field
+// has none
+func (field *Field) LookupTag(key string) *StructTag {
+	for _, tag := range field.Tags {
+		if tag.Key == key {
+			return tag
+		}
+	}
+	return nil
+}
+
+// parseStructTag splits a struct tag literal, including its quotes, into its
+// keys following the conventional `key:"value" key:"value"` format. Parsing
+// stops at the first malformed key like reflect.StructTag.Lookup does.
+func parseStructTag(literal string) []*StructTag {
+	tag, err := strconv.Unquote(literal)
+	if err != nil {
+		return nil
+	}
+
+	var tags []*StructTag
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			break
+		}
+
+		// Keys run up to the colon and can't contain spaces, quotes or controls
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// The value is a quoted Go string
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			break
+		}
+		tag = tag[i+1:]
+
+		parts := strings.Split(value, ",")
+		tags = append(tags, &StructTag{
+			Key:     key,
+			Name:    parts[0],
+			Options: parts[1:],
+		})
+	}
+
+	return tags
+}
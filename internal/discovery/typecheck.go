@@ -0,0 +1,346 @@
+package discovery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// typeChecker type-checks packages with go/types. Packages inside the
+// project's modules are parsed and checked from source on demand, everything
+// else (standard library and third-party dependencies) is resolved by the
+// source importer.
+//
+// Every package is loaded once. Packages with different import paths are
+// checked concurrently, while importers of a package that is being loaded
+// wait for it. The source importer keeps an unsynchronized cache of its own,
+// so imports from outside the project are serialized through fallbackMu.
+type typeChecker struct {
+	fileSet  *token.FileSet
+	modules  []*Module
+	parseDir func(dir string) ([]*ast.File, error)
+
+	fallbackMu sync.Mutex
+	fallback   types.ImporterFrom
+
+	mu        sync.Mutex // Guards packages and blockedOn
+	packages  map[string]*checkedPackage
+	blockedOn map[string]string // Import each package being checked is waiting for
+}
+
+// checkedPackage records the outcome of type-checking a single package
+type checkedPackage struct {
+	done chan struct{} // Closed once pkg and err are set
+	pkg  *types.Package
+	err  error
+}
+
+// packageImporter resolves the imports of a single package for go/types
+type packageImporter struct {
+	tc   *typeChecker
+	path string // Import path of the importing package
+}
+
+// newTypeChecker creates a type checker for the given modules
+func newTypeChecker(fileSet *token.FileSet, modules []*Module, parseDir func(dir string) ([]*ast.File, error)) *typeChecker {
+	fallback, _ := importer.ForCompiler(fileSet, "source", nil).(types.ImporterFrom)
+
+	return &typeChecker{
+		fileSet:   fileSet,
+		modules:   modules,
+		parseDir:  parseDir,
+		fallback:  fallback,
+		packages:  make(map[string]*checkedPackage),
+		blockedOn: make(map[string]string),
+	}
+}
+
+// Import implements types.Importer
+func (pi *packageImporter) Import(path string) (*types.Package, error) {
+	dir := ""
+	if len(pi.tc.modules) > 0 {
+		dir = pi.tc.modules[0].Dir
+	}
+	return pi.ImportFrom(path, dir, 0)
+}
+
+// ImportFrom implements types.ImporterFrom
+func (pi *packageImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	tc := pi.tc
+
+	pkgDir, ok := tc.moduleDirFor(path)
+	if !ok {
+		return tc.load(pi.path, path, func() (*types.Package, error) {
+			if tc.fallback == nil {
+				return nil, fmt.Errorf("no importer available for %s", path)
+			}
+			tc.fallbackMu.Lock()
+			defer tc.fallbackMu.Unlock()
+			// Resolve dependencies from the importing package's directory so
+			// the go command picks the right module or workspace
+			return tc.fallback.ImportFrom(path, dir, 0)
+		})
+	}
+
+	return tc.load(pi.path, path, func() (*types.Package, error) {
+		files, err := tc.parseDir(pkgDir)
+		if err != nil {
+			return nil, err
+		}
+		return tc.check(path, files)
+	})
+}
+
+// load returns the package with the given import path, calling load for the
+// first request only. Later requests wait for the outcome, unless the package
+// is, directly or through its own imports, waiting for the importing package:
+// such an import cycle fails instead of blocking forever. importer is the
+// import path of the package requesting path, empty for discovery itself.
+func (tc *typeChecker) load(importer, path string, load func() (*types.Package, error)) (*types.Package, error) {
+	tc.mu.Lock()
+	checked, loading := tc.packages[path]
+	if loading {
+		select {
+		case <-checked.done:
+			tc.mu.Unlock()
+			return checked.pkg, checked.err
+		default:
+		}
+		if importer != "" && tc.waitsFor(path, importer) {
+			tc.mu.Unlock()
+			return nil, fmt.Errorf("import cycle through %s", path)
+		}
+	} else {
+		checked = &checkedPackage{done: make(chan struct{})}
+		tc.packages[path] = checked
+	}
+	if importer != "" {
+		tc.blockedOn[importer] = path
+	}
+	tc.mu.Unlock()
+
+	if loading {
+		<-checked.done
+	} else {
+		checked.pkg, checked.err = load()
+		close(checked.done)
+	}
+
+	if importer != "" {
+		tc.mu.Lock()
+		delete(tc.blockedOn, importer)
+		tc.mu.Unlock()
+	}
+	return checked.pkg, checked.err
+}
+
+// waitsFor reports whether the package with the given import path is target
+// or waits for it through the imports being loaded. tc.mu must be held.
+func (tc *typeChecker) waitsFor(path, target string) bool {
+	for ok := true; ok; path, ok = tc.blockedOn[path] {
+		if path == target {
+			return true
+		}
+	}
+	return false
+}
+
+// check type-checks the given files as the package with the given import
+// path. It must run inside load, which records the outcome.
+func (tc *typeChecker) check(path string, files []*ast.File) (*types.Package, error) {
+	var firstErr error
+	conf := types.Config{
+		Importer: &packageImporter{tc: tc, path: path},
+		Error: func(err error) {
+			// Soft errors such as unused imports don't affect the resolved objects
+			if typeErr, ok := err.(types.Error); ok && typeErr.Soft {
+				return
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+
+	pkg, _ := conf.Check(path, tc.fileSet, files, nil)
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return pkg, nil
+}
+
+// moduleDirFor maps an import path inside one of the project's modules to its
+// directory, preferring the module with the longest matching path
+func (tc *typeChecker) moduleDirFor(path string) (string, bool) {
+	var best *Module
+	for _, module := range tc.modules {
+		if module.Path == "" || (path != module.Path && !strings.HasPrefix(path, module.Path+"/")) {
+			continue
+		}
+		if best == nil || len(module.Path) > len(best.Path) {
+			best = module
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+
+	rel := strings.TrimPrefix(strings.TrimPrefix(path, best.Path), "/")
+	return filepath.Join(best.Dir, filepath.FromSlash(rel)), true
+}
+
+// typeCheck runs the type-checking pass for a parsed package. It returns nil
+// when the package cannot be type-checked, in which case discovery continues
+// with AST information only. A package that was already loaded as a
+// dependency is not checked again.
+func (d *Discoverer) typeCheck(importPath string, astFiles map[string]*ast.File) *types.Package {
+	pkg, err := d.checker.load("", importPath, func() (*types.Package, error) {
+		return d.checker.check(importPath, sortedFiles(astFiles))
+	})
+	if err != nil {
+		fmt.Printf("Warning: type-checking %s failed, using AST-only discovery: %v\n", importPath, err)
+		return nil
+	}
+	return pkg
+}
+
+// parseDirForTypes parses the non-test Go files of a package directory that
+// are built on the primary platform, for the type checker
+func (d *Discoverer) parseDirForTypes(dir string) ([]*ast.File, error) {
+	name, files, err := d.selectPackageFiles(dir, false)
+	if err != nil {
+		return nil, err
+	}
+	if name == "main" {
+		return nil, fmt.Errorf("cannot import main package in %s", dir)
+	}
+
+	astPkg, err := d.parseSourceFiles(name, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedFiles(d.primaryPlatformFiles(astPkg, files)), nil
+}
+
+// sortedFiles returns the files of a package ordered by filename
+func sortedFiles(astFiles map[string]*ast.File) []*ast.File {
+	filenames := make([]string, 0, len(astFiles))
+	for filename := range astFiles {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	files := make([]*ast.File, len(filenames))
+	for i, filename := range filenames {
+		files[i] = astFiles[filename]
+	}
+	return files
+}
+
+// lookupObject returns the package-level object with the given name
+func lookupObject(pkg *types.Package, name string) types.Object {
+	if pkg == nil {
+		return nil
+	}
+	return pkg.Scope().Lookup(name)
+}
+
+// lookupMethod returns the method with the given name declared on a named
+// type, or the interface method for interface types
+func lookupMethod(obj types.Object, name string) types.Object {
+	if obj == nil {
+		return nil
+	}
+
+	if named, ok := obj.Type().(*types.Named); ok {
+		for i := 0; i < named.NumMethods(); i++ {
+			if method := named.Method(i); method.Name() == name {
+				return method
+			}
+		}
+	}
+
+	if iface, ok := obj.Type().Underlying().(*types.Interface); ok {
+		for i := 0; i < iface.NumMethods(); i++ {
+			if method := iface.Method(i); method.Name() == name {
+				return method
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupField returns the struct field with the given name. Embedded fields
+// are named after their type.
+func lookupField(obj types.Object, name string) types.Object {
+	if obj == nil {
+		return nil
+	}
+
+	structType, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	for i := 0; i < structType.NumFields(); i++ {
+		if field := structType.Field(i); field.Name() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// typeBaseName returns the unqualified name of a type expression without
+// pointers and type arguments. It is the implicit name of an embedded field
+// and the receiver base type of a method.
+func typeBaseName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeBaseName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return typeBaseName(t.X)
+	case *ast.IndexListExpr:
+		return typeBaseName(t.X)
+	default:
+		return ""
+	}
+}
+
+// typeKindOf derives the TypeKind of a type from its resolved object
+func typeKindOf(obj types.Object) string {
+	typeName, ok := obj.(*types.TypeName)
+	if !ok {
+		return ""
+	}
+
+	if typeName.IsAlias() {
+		return "alias"
+	}
+
+	switch underlying := typeName.Type().Underlying().(type) {
+	case *types.Struct:
+		return "struct"
+	case *types.Interface:
+		if !underlying.IsMethodSet() {
+			return "constraint"
+		}
+		return "interface"
+	default:
+		return "type"
+	}
+}
@@ -0,0 +1,107 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kolosys/proton/internal/config"
+)
+
+// discoverModule discovers the packages of a module made of the given files,
+// failing the test if discovery doesn't finish in time
+func discoverModule(t *testing.T, files map[string]string) map[string]*PackageInfo {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/shapes\n\ngo 1.24\n"
+	files[".proton/config.yml"] = "repository:\n  name: shapes\n  import_path: example.com/shapes\ndiscovery:\n  max_concurrency: 4\ncache:\n  enabled: false\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := config.Load("", dir)
+	if err != nil {
+		t.Fatalf("loading configuration: %v", err)
+	}
+
+	done := make(chan []*PackageInfo)
+	go func() {
+		packages, err := New(cfg, dir).DiscoverPackages()
+		if err != nil {
+			t.Errorf("discovering packages: %v", err)
+		}
+		done <- packages
+	}()
+
+	select {
+	case packages := <-done:
+		byPath := make(map[string]*PackageInfo)
+		for _, pkg := range packages {
+			byPath[pkg.ImportPath] = pkg
+		}
+		return byPath
+	case <-time.After(time.Minute):
+		t.Fatal("discovery didn't finish")
+		return nil
+	}
+}
+
+func TestTypeCheckSharesDependencies(t *testing.T) {
+	packages := discoverModule(t, map[string]string{
+		"shape/shape.go": "// Package shape declares shapes.\npackage shape\n\n// Shape has an area.\ntype Shape interface{ Area() float64 }\n",
+		"square/square.go": "// Package square declares squares.\npackage square\n\nimport \"example.com/shapes/shape\"\n\n" +
+			"// Of returns a square.\nfunc Of() shape.Shape { return nil }\n",
+		"circle/circle.go": "// Package circle declares circles.\npackage circle\n\nimport \"example.com/shapes/shape\"\n\n" +
+			"// Of returns a circle.\nfunc Of() shape.Shape { return nil }\n",
+	})
+
+	var shapes []*PackageInfo
+	for _, path := range []string{"example.com/shapes/shape", "example.com/shapes/square", "example.com/shapes/circle"} {
+		pkg := packages[path]
+		if pkg == nil || pkg.Package == nil {
+			t.Fatalf("package %s wasn't type-checked", path)
+		}
+		shapes = append(shapes, pkg)
+	}
+
+	// Packages checked concurrently resolve the same types for their imports
+	shape := shapes[0].Package.Scope().Lookup("Shape")
+	for _, pkg := range shapes[1:] {
+		result := pkg.Package.Scope().Lookup("Of").Type().Underlying()
+		if got := result.String(); got != "func() example.com/shapes/shape.Shape" {
+			t.Fatalf("%s.Of has type %s", pkg.ImportPath, got)
+		}
+		for _, imported := range pkg.Package.Imports() {
+			if imported.Scope().Lookup("Shape") != shape {
+				t.Errorf("%s imports a second copy of %s", pkg.ImportPath, imported.Path())
+			}
+		}
+	}
+}
+
+func TestTypeCheckImportCycle(t *testing.T) {
+	packages := discoverModule(t, map[string]string{
+		"a/a.go": "// Package a imports b.\npackage a\n\nimport \"example.com/shapes/b\"\n\n// A uses b.\nvar A = b.B\n",
+		"b/b.go": "// Package b imports a.\npackage b\n\nimport \"example.com/shapes/a\"\n\n// B uses a.\nvar B = a.A\n",
+	})
+
+	// The cycle fails type-checking instead of blocking, discovery goes on
+	// without type information
+	for _, path := range []string{"example.com/shapes/a", "example.com/shapes/b"} {
+		pkg := packages[path]
+		if pkg == nil {
+			t.Fatalf("package %s wasn't discovered", path)
+		}
+		if pkg.Package != nil {
+			t.Errorf("package %s was type-checked despite the import cycle", path)
+		}
+	}
+}
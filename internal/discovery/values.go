@@ -0,0 +1,73 @@
+package discovery
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// valueEntries lists the documented names of a const or var declaration with
+// their types, computed constant values and the comments of their specs
+func valueEntries(decl *ast.GenDecl, typesPkg *types.Package, documented func(string) bool) []*ValueEntry {
+	var entries []*ValueEntry
+	for _, spec := range decl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+
+		doc := valueSpec.Doc.Text()
+		if doc == "" {
+			doc = valueSpec.Comment.Text()
+		}
+
+		for _, name := range valueSpec.Names {
+			if !documented(name.Name) {
+				continue
+			}
+
+			entry := &ValueEntry{Name: name.Name, Exported: name.IsExported(), Doc: doc}
+			if obj := lookupObject(typesPkg, name.Name); obj != nil {
+				entry.Type = types.TypeString(obj.Type(), types.RelativeTo(typesPkg))
+				if constant, ok := obj.(*types.Const); ok {
+					entry.Value = constant.Val().String()
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// usesIota reports whether a declaration is a const group using iota
+func usesIota(decl *ast.GenDecl) bool {
+	if decl.Tok != token.CONST {
+		return false
+	}
+
+	found := false
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == "iota" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// declComments returns the comments inside a declaration, excluding its doc
+// comment, so the printed declaration keeps the comments of its specs
+func declComments(fileSet *token.FileSet, astPkg *ast.Package, decl *ast.GenDecl) []*ast.CommentGroup {
+	file := astPkg.Files[fileSet.Position(decl.Pos()).Filename]
+	if file == nil {
+		return nil
+	}
+
+	var comments []*ast.CommentGroup
+	for _, group := range file.Comments {
+		if group != decl.Doc && group.Pos() >= decl.Pos() && group.End() <= decl.End() {
+			comments = append(comments, group)
+		}
+	}
+	return comments
+}
@@ -0,0 +1,260 @@
+package examples
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kolosys/proton/internal/modfile"
+	"github.com/kolosys/proton/internal/projectfs"
+)
+
+// Directory is an example directory of the project: a program, a package or
+// a category of further examples
+type Directory struct {
+	Name           string
+	Dir            string // Absolute path of the directory
+	Path           string // Slash-separated path relative to the project root
+	Readme         string // Content of the directory's own README.md
+	Doc            string // Package doc comment of its Go files
+	Package        string // Package name of its Go files, empty without any
+	Program        bool   // The package is a program declaring func main
+	Tests          bool   // The directory has _test.go files
+	Sources        []*SourceFile
+	Assets         []string // Images the README may refer to, relative to Dir
+	Entries        []*Entry // File tree below the directory
+	Module         *Module  // Module declared by a go.mod in the directory, nil if it has none
+	Subdirectories []*Directory
+}
+
+// SourceFile is a Go file of an example directory
+type SourceFile struct {
+	Name    string
+	Content string
+}
+
+// Entry is a file or directory of an example's file tree
+type Entry struct {
+	Name  string
+	Dir   bool
+	Depth int  // Nesting level, 0 for entries of the example directory
+	Last  bool // The last entry of its parent directory
+}
+
+// Module is the go.mod of an example with its own module
+type Module struct {
+	Path      string
+	GoVersion string
+	Requires  []modfile.Require
+}
+
+// assetExtensions are the file types copied along with an example's page
+var assetExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true,
+}
+
+// LoadDirectory reads the example directory dir of a project, and its
+// subdirectories. Hidden directories, vendor and testdata contribute to the
+// file tree only.
+func LoadDirectory(files *projectfs.FS, dir string) (*Directory, error) {
+	relPath, err := filepath.Rel(files.Root(), dir)
+	if err != nil {
+		relPath = filepath.Base(dir)
+	}
+
+	directory := &Directory{
+		Name: filepath.Base(dir),
+		Dir:  dir,
+		Path: filepath.ToSlash(relPath),
+	}
+
+	entries, err := files.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read example directory %s: %w", dir, err)
+	}
+
+	var goFiles []*ast.File
+	fileSet := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+
+		if entry.IsDir() {
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" {
+				continue
+			}
+			subdirectory, err := LoadDirectory(files, path)
+			if err != nil {
+				return nil, err
+			}
+
+			// Directories without Go files or a README only hold assets
+			if len(subdirectory.Sources) == 0 && subdirectory.Readme == "" && len(subdirectory.Subdirectories) == 0 {
+				for _, asset := range subdirectory.Assets {
+					directory.Assets = append(directory.Assets, filepath.Join(name, asset))
+				}
+				continue
+			}
+			directory.Subdirectories = append(directory.Subdirectories, subdirectory)
+			continue
+		}
+
+		switch {
+		case strings.EqualFold(name, "README.md"):
+			data, err := files.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			directory.Readme = strings.TrimSpace(string(data))
+		case name == "go.mod":
+			data, err := files.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			directory.Module = &Module{
+				Path:      modfile.ModulePath(data),
+				GoVersion: modfile.GoVersion(data),
+				Requires:  modfile.Requires(data),
+			}
+		case strings.HasSuffix(name, ".go"):
+			data, err := files.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			directory.Sources = append(directory.Sources, &SourceFile{Name: name, Content: strings.TrimRight(string(data), "\n")})
+			if strings.HasSuffix(name, "_test.go") {
+				directory.Tests = true
+				continue
+			}
+			if file, err := parser.ParseFile(fileSet, path, data, parser.ParseComments); err == nil {
+				goFiles = append(goFiles, file)
+			}
+		case assetExtensions[strings.ToLower(filepath.Ext(name))]:
+			directory.Assets = append(directory.Assets, name)
+		}
+	}
+
+	for _, file := range goFiles {
+		// A main package wins over other package clauses, e.g. of files
+		// excluded by build constraints
+		if directory.Package == "" || directory.Package != "main" && file.Name.Name == "main" {
+			directory.Package = file.Name.Name
+		}
+		if file.Doc != nil && directory.Doc == "" {
+			directory.Doc = strings.TrimSpace(file.Doc.Text())
+		}
+		if IsMainProgram(file) {
+			directory.Program = true
+		}
+	}
+
+	directory.Entries = fileTree(files, dir, 0)
+	return directory, nil
+}
+
+// Summary returns the first sentence of the directory's doc comment, or the
+// first paragraph of its README
+func (d *Directory) Summary() string {
+	text := d.Doc
+	if text == "" {
+		for _, paragraph := range strings.Split(d.Readme, "\n\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" && !strings.HasPrefix(paragraph, "#") {
+				text = paragraph
+				break
+			}
+		}
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+	if i := strings.Index(text, ". "); i >= 0 {
+		text = text[:i+1]
+	}
+	return text
+}
+
+// Tree draws the file tree of the directory, e.g.
+//
+//	├── main.go
+//	└── config/
+//	    └── config.yaml
+func (d *Directory) Tree() string {
+	var b strings.Builder
+	b.WriteString(d.Name + "/\n")
+
+	// open tracks which ancestors have entries after the current one
+	var open []bool
+	for _, entry := range d.Entries {
+		open = append(open[:entry.Depth], !entry.Last)
+		for _, more := range open[:entry.Depth] {
+			if more {
+				b.WriteString("│   ")
+			} else {
+				b.WriteString("    ")
+			}
+		}
+		if entry.Last {
+			b.WriteString("└── ")
+		} else {
+			b.WriteString("├── ")
+		}
+		b.WriteString(entry.Name)
+		if entry.Dir {
+			b.WriteString("/")
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// fileTree lists the files and directories below dir, directories first.
+// Hidden entries are left out.
+func fileTree(files *projectfs.FS, dir string, depth int) []*Entry {
+	dirEntries, err := files.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var visible []fs.DirEntry
+	for _, entry := range dirEntries {
+		if !strings.HasPrefix(entry.Name(), ".") {
+			visible = append(visible, entry)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].IsDir() && !visible[j].IsDir()
+	})
+
+	var entries []*Entry
+	for i, entry := range visible {
+		entries = append(entries, &Entry{
+			Name:  entry.Name(),
+			Dir:   entry.IsDir(),
+			Depth: depth,
+			Last:  i == len(visible)-1,
+		})
+		if entry.IsDir() {
+			entries = append(entries, fileTree(files, filepath.Join(dir, entry.Name()), depth+1)...)
+		}
+	}
+	return entries
+}
+
+// IsMainProgram reports whether a file declares the main function of a
+// program
+func IsMainProgram(file *ast.File) bool {
+	if file.Name.Name != "main" {
+		return false
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+// Package examples builds and runs the examples of a project with the local
+// Go toolchain, so the documentation shows their real output.
+package examples
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Runner builds example programs and test binaries into a temporary
+// directory and runs them with a time limit
+type Runner struct {
+	timeout time.Duration
+	tempDir string
+	builds  int // Number of binaries built, names them uniquely
+}
+
+// NewRunner creates a runner whose runs are limited to timeout. Close
+// removes its temporary directory.
+func NewRunner(timeout time.Duration) (*Runner, error) {
+	tempDir, err := os.MkdirTemp("", "proton-examples-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	return &Runner{timeout: timeout, tempDir: tempDir}, nil
+}
+
+// Close removes the binaries and working directories of the runner
+func (r *Runner) Close() error {
+	return os.RemoveAll(r.tempDir)
+}
+
+// RunProgram builds the main package in dir and runs it in an empty
+// temporary directory, returning what it writes to standard output
+func (r *Runner) RunProgram(dir string) (string, error) {
+	binary := r.binaryPath("example")
+	if _, err := r.goCommand(dir, "build", "-o", binary, "."); err != nil {
+		return "", fmt.Errorf("failed to build example %s: %w", dir, err)
+	}
+
+	workDir, err := os.MkdirTemp(r.tempDir, "run-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	stdout, err := r.run(workDir, binary)
+	if err != nil {
+		return "", fmt.Errorf("example %s failed: %w", dir, err)
+	}
+	return stdout, nil
+}
+
+// VerifyExamples runs the Example functions with the given names of the
+// package in dir, e.g. "ExampleBuffer_Write". Functions without an output
+// comment are only compiled by go test, the others fail when their output
+// doesn't match. The test binary runs in the package directory like under
+// go test.
+func (r *Runner) VerifyExamples(dir string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	binary := r.binaryPath("examples.test")
+	if _, err := r.goCommand(dir, "test", "-c", "-o", binary, "."); err != nil {
+		return fmt.Errorf("failed to build examples of %s: %w", dir, err)
+	}
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	pattern := "^(" + strings.Join(quoted, "|") + ")$"
+
+	if _, err := r.run(dir, binary, "-test.run", pattern); err != nil {
+		return fmt.Errorf("examples of %s don't match their output: %w", dir, err)
+	}
+	return nil
+}
+
+// binaryPath returns a unique path for a binary in the temporary directory
+func (r *Runner) binaryPath(name string) string {
+	r.builds++
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(r.tempDir, fmt.Sprintf("%d-%s", r.builds, name))
+}
+
+// goCommand runs the go tool in dir. Builds are not subject to the timeout,
+// only the examples themselves are.
+func (r *Runner) goCommand(dir string, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("go %s: %w\n%s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// run runs a binary in dir within the timeout and returns its standard
+// output. Failures report both output streams.
+func (r *Runner) run(dir, binary string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("timed out after %s", r.timeout)
+	}
+	if err != nil {
+		output := strings.TrimSpace(stdout.String() + stderr.String())
+		return "", fmt.Errorf("%w\n%s", err, output)
+	}
+	return stdout.String(), nil
+}
@@ -2,12 +2,17 @@ package generator
 
 import (
 	"fmt"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/coverage"
 	"github.com/kolosys/proton/internal/discovery"
+	"github.com/kolosys/proton/internal/examples"
+	"github.com/kolosys/proton/internal/projectfs"
 	"github.com/kolosys/proton/internal/templates"
 )
 
@@ -18,6 +23,17 @@ type Generator struct {
 	outputPath  string
 	discoverer  *discovery.Discoverer
 	templates   *templates.Engine
+	written     map[string]bool // Output files produced by the current run
+	files       *projectfs.FS   // Sources of the documented version, the work tree or a git revision
+
+	// Versions documented next to the work tree, and the version documented
+	// by this generator, nil for the work tree
+	versions []*templates.Version
+	version  *templates.Version
+
+	// Runs examples when discovery.examples.verify is set, nil otherwise
+	runner         *examples.Runner
+	programOutputs map[string]string // Output of example programs by directory
 }
 
 // New creates a new documentation generator
@@ -43,18 +59,13 @@ func New(cfg *config.Config, projectPath string) (*Generator, error) {
 		outputPath:  outputPath,
 		discoverer:  discoverer,
 		templates:   templateEngine,
+		written:     make(map[string]bool),
+		files:       projectfs.Dir(projectPath),
 	}, nil
 }
 
 // Generate performs the complete documentation generation
 func (g *Generator) Generate() error {
-	// Clean output directory if requested
-	if g.config.Output.Clean {
-		if err := g.cleanOutputDirectory(); err != nil {
-			return fmt.Errorf("failed to clean output directory: %w", err)
-		}
-	}
-
 	// Ensure output directory exists
 	if err := os.MkdirAll(g.outputPath, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -66,6 +77,61 @@ func (g *Generator) Generate() error {
 		return fmt.Errorf("package discovery failed: %w", err)
 	}
 
+	// Resolve cross-references between the discovered packages
+	g.templates.SetSymbolIndex(g.discoverer.SymbolIndex())
+
+	// Verify the examples with the local Go toolchain, so stale output fails
+	// generation before any page shows it
+	if g.config.Discovery.Examples.Verify {
+		runner, err := examples.NewRunner(g.config.Discovery.Examples.RunTimeout())
+		if err != nil {
+			return fmt.Errorf("failed to create example runner: %w", err)
+		}
+		defer runner.Close()
+		g.runner = runner
+		g.programOutputs = make(map[string]string)
+
+		if err := g.verifyExamples(packages); err != nil {
+			return fmt.Errorf("example verification failed: %w", err)
+		}
+	}
+
+	// Discover the versions first, so the pages of the work tree can link
+	// to them
+	var versions []*versionDocs
+	if g.config.Versions.Enabled {
+		versions, err = g.discoverVersions()
+		defer closeVersions(versions)
+		if err != nil {
+			return fmt.Errorf("version discovery failed: %w", err)
+		}
+		g.setVersions(versions)
+	}
+
+	if err := g.render(packages); err != nil {
+		return err
+	}
+
+	// Generate the documentation of every version
+	if g.config.Versions.Enabled {
+		if err := g.generateVersions(versions); err != nil {
+			return fmt.Errorf("failed to generate versioned documentation: %w", err)
+		}
+	}
+
+	// Clean output directory if requested. Files are only removed once
+	// generation succeeded, and unchanged files are left untouched.
+	if g.config.Output.Clean {
+		if err := g.cleanOutputDirectory(); err != nil {
+			return fmt.Errorf("failed to clean output directory: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// render generates the documentation of the discovered packages
+func (g *Generator) render(packages []*discovery.PackageInfo) error {
 	// Create template context
 	context := g.createTemplateContext(packages)
 
@@ -79,6 +145,13 @@ func (g *Generator) Generate() error {
 		return fmt.Errorf("failed to generate package documentation: %w", err)
 	}
 
+	// Generate one section per module for multi-module repositories
+	if len(context.Modules) > 1 {
+		if err := g.generateModuleDocumentation(context); err != nil {
+			return fmt.Errorf("failed to generate module documentation: %w", err)
+		}
+	}
+
 	// Generate API reference documentation
 	if g.config.Discovery.APIGeneration.Enabled {
 		if err := g.generateAPIDocumentation(packages, context); err != nil {
@@ -93,6 +166,20 @@ func (g *Generator) Generate() error {
 		}
 	}
 
+	// Generate the command line reference
+	if context.CLI != nil {
+		if err := g.generateCLIReference(context); err != nil {
+			return fmt.Errorf("failed to generate CLI reference: %w", err)
+		}
+	}
+
+	// Generate the operations reference
+	if context.Operations != nil {
+		if err := g.generateOperationsReference(context); err != nil {
+			return fmt.Errorf("failed to generate operations reference: %w", err)
+		}
+	}
+
 	// Generate guides documentation
 	if g.config.Discovery.Guides.Enabled {
 		if err := g.generateGuidesDocumentation(context); err != nil {
@@ -110,42 +197,41 @@ func (g *Generator) Generate() error {
 	return nil
 }
 
-// cleanOutputDirectory removes all files from the output directory
-func (g *Generator) cleanOutputDirectory() error {
-	if _, err := os.Stat(g.outputPath); os.IsNotExist(err) {
-		return nil // Directory doesn't exist, nothing to clean
-	}
-
-	entries, err := os.ReadDir(g.outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to read output directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		path := filepath.Join(g.outputPath, entry.Name())
-		if err := os.RemoveAll(path); err != nil {
-			return fmt.Errorf("failed to remove %s: %w", path, err)
-		}
-	}
-
-	return nil
-}
-
 // createTemplateContext creates the context object for template rendering
 func (g *Generator) createTemplateContext(packages []*discovery.PackageInfo) *templates.Context {
-	return &templates.Context{
+	context := &templates.Context{
 		Repository: g.config.Repository,
 		Packages:   packages,
+		Modules:    g.discoverer.Modules(),
 		Config:     g.config,
 		Metadata:   g.config.Metadata,
+		Versions:   g.versions,
+		Version:    g.version,
+	}
+
+	// The CLI reference is only generated for projects defining commands
+	if g.config.Discovery.CLIReference.Enabled {
+		if reference := discovery.NewCLIReference(packages); len(reference.Commands) > 0 {
+			context.CLI = reference
+		}
 	}
+	if g.config.Discovery.Operations.Enabled {
+		if reference := discovery.NewOperationsReference(packages); len(reference.Binaries) > 0 {
+			context.Operations = reference
+		}
+	}
+	if g.config.Coverage.Page && g.config.Discovery.APIGeneration.Enabled {
+		context.Coverage = coverage.New(packages)
+	}
+
+	return context
 }
 
 // generateMainFiles generates the main documentation files
 func (g *Generator) generateMainFiles(context *templates.Context) error {
 	// Generate main README/index
 	indexPath := filepath.Join(g.outputPath, "README.md")
-	if err := g.templates.RenderToFile("index", context, indexPath); err != nil {
+	if err := g.renderToFile("index", context, indexPath); err != nil {
 		return fmt.Errorf("failed to generate main index: %w", err)
 	}
 
@@ -162,7 +248,7 @@ func (g *Generator) generatePackageDocumentation(packages []*discovery.PackageIn
 
 	// Generate getting-started index
 	gettingStartedIndexPath := filepath.Join(gettingStartedDir, "README.md")
-	if err := g.templates.RenderToFile("getting-started-index", context, gettingStartedIndexPath); err != nil {
+	if err := g.renderToFile("getting-started-index", context, gettingStartedIndexPath); err != nil {
 		return fmt.Errorf("failed to generate getting-started index: %w", err)
 	}
 
@@ -173,8 +259,8 @@ func (g *Generator) generatePackageDocumentation(packages []*discovery.PackageIn
 			Package: pkg,
 		}
 
-		pkgPath := filepath.Join(gettingStartedDir, fmt.Sprintf("%s.md", pkg.Name))
-		if err := g.templates.RenderToFile("getting-started", pkgContext, pkgPath); err != nil {
+		pkgPath := filepath.Join(gettingStartedDir, fmt.Sprintf("%s.md", pkg.Slug))
+		if err := g.renderToFile("getting-started", pkgContext, pkgPath); err != nil {
 			return fmt.Errorf("failed to generate getting-started documentation for package %s: %w", pkg.Name, err)
 		}
 	}
@@ -182,6 +268,23 @@ func (g *Generator) generatePackageDocumentation(packages []*discovery.PackageIn
 	return nil
 }
 
+// generateModuleDocumentation generates an index page for every module
+func (g *Generator) generateModuleDocumentation(context *templates.Context) error {
+	for _, module := range context.Modules {
+		moduleContext := &templates.ModuleContext{
+			Context: context,
+			Module:  module,
+		}
+
+		modulePath := filepath.Join(g.outputPath, "modules", module.Slug, "README.md")
+		if err := g.renderToFile("module-index", moduleContext, modulePath); err != nil {
+			return fmt.Errorf("failed to generate index for module %s: %w", module.Path, err)
+		}
+	}
+
+	return nil
+}
+
 // generateAPIDocumentation generates API reference documentation
 func (g *Generator) generateAPIDocumentation(packages []*discovery.PackageInfo, context *templates.Context) error {
 	// Create API reference directory
@@ -192,7 +295,7 @@ func (g *Generator) generateAPIDocumentation(packages []*discovery.PackageInfo,
 
 	// Generate API reference index
 	apiIndexPath := filepath.Join(apiDir, "README.md")
-	if err := g.templates.RenderToFile("index-api-reference", context, apiIndexPath); err != nil {
+	if err := g.renderToFile("index-api-reference", context, apiIndexPath); err != nil {
 		return fmt.Errorf("failed to generate API reference index: %w", err)
 	}
 
@@ -203,12 +306,102 @@ func (g *Generator) generateAPIDocumentation(packages []*discovery.PackageInfo,
 			Package: pkg,
 		}
 
-		apiPath := filepath.Join(apiDir, fmt.Sprintf("%s.md", pkg.Name))
-		if err := g.templates.RenderToFile("api-reference", pkgContext, apiPath); err != nil {
+		apiPath := filepath.Join(g.outputPath, filepath.FromSlash(discovery.APIReferencePage(pkg)))
+		if err := g.renderToFile("api-reference", pkgContext, apiPath); err != nil {
 			return fmt.Errorf("failed to generate API reference for package %s: %w", pkg.Name, err)
 		}
 	}
 
+	// Generate the deprecated API index when anything is deprecated
+	if len(g.templates.DeprecatedSymbols(packages)) > 0 {
+		deprecatedPath := filepath.Join(apiDir, "deprecated.md")
+		if err := g.renderToFile("deprecated", context, deprecatedPath); err != nil {
+			return fmt.Errorf("failed to generate deprecated API index: %w", err)
+		}
+	}
+
+	// Generate the documentation coverage page, named so it can't collide
+	// with the page of a package
+	if context.Coverage != nil {
+		coveragePath := filepath.Join(apiDir, "documentation-coverage.md")
+		if err := g.renderToFile("coverage", context, coveragePath); err != nil {
+			return fmt.Errorf("failed to generate coverage page: %w", err)
+		}
+	}
+
+	// Generate the configuration reference for the configured root struct
+	if refConfig := g.config.Discovery.ConfigReference; refConfig.Enabled {
+		reference, err := discovery.NewConfigReference(g.discoverer.SymbolIndex(), refConfig.Type, refConfig.Tag)
+		if err != nil && g.version != nil {
+			// Older versions may predate the configured type
+			fmt.Printf("Warning: no configuration reference for %s: %v\n", g.version.Name, err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to build configuration reference: %w", err)
+		}
+
+		refContext := &templates.ConfigReferenceContext{
+			Context:   context,
+			Reference: reference,
+		}
+
+		refPath := filepath.Join(apiDir, "config-reference.md")
+		if err := g.renderToFile("config-reference", refContext, refPath); err != nil {
+			return fmt.Errorf("failed to generate configuration reference: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// generateCLIReference generates an index of the command tree and a page
+// per command
+func (g *Generator) generateCLIReference(context *templates.Context) error {
+	cliDir := filepath.Join(g.outputPath, "cli-reference")
+
+	indexPath := filepath.Join(cliDir, "README.md")
+	if err := g.renderToFile("cli-reference-index", context, indexPath); err != nil {
+		return fmt.Errorf("failed to generate CLI reference index: %w", err)
+	}
+
+	for _, command := range context.CLI.Commands {
+		commandContext := &templates.CLICommandContext{
+			Context: context,
+			Command: command,
+		}
+
+		commandPath := filepath.Join(cliDir, command.Page())
+		if err := g.renderToFile("cli-command", commandContext, commandPath); err != nil {
+			return fmt.Errorf("failed to generate CLI reference for command %s: %w", command.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// generateOperationsReference generates an index of the binaries and a page
+// per binary listing its flags and environment variables
+func (g *Generator) generateOperationsReference(context *templates.Context) error {
+	operationsDir := filepath.Join(g.outputPath, "operations")
+
+	indexPath := filepath.Join(operationsDir, "README.md")
+	if err := g.renderToFile("operations-index", context, indexPath); err != nil {
+		return fmt.Errorf("failed to generate operations index: %w", err)
+	}
+
+	for _, binary := range context.Operations.Binaries {
+		binaryContext := &templates.BinaryContext{
+			Context: context,
+			Binary:  binary,
+		}
+
+		binaryPath := filepath.Join(operationsDir, binary.Name+".md")
+		if err := g.renderToFile("operations", binaryContext, binaryPath); err != nil {
+			return fmt.Errorf("failed to generate operations reference for %s: %w", binary.Name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -222,10 +415,44 @@ func (g *Generator) generateExamplesDocumentation(packages []*discovery.PackageI
 
 	// Generate examples index
 	examplesIndexPath := filepath.Join(examplesDir, "README.md")
-	if err := g.templates.RenderToFile("examples-index", context, examplesIndexPath); err != nil {
+	if err := g.renderToFile("examples-index", context, examplesIndexPath); err != nil {
 		return fmt.Errorf("failed to generate examples index: %w", err)
 	}
 
+	// Generate a page of testable examples for each package, with whole-file
+	// examples on pages of their own
+	for _, pkg := range packages {
+		if len(pkg.Examples) == 0 {
+			continue
+		}
+
+		pkgContext := &templates.PackageContext{
+			Context: context,
+			Package: pkg,
+		}
+
+		pkgExamplesDir := filepath.Join(examplesDir, pkg.Slug)
+		if err := g.renderToFile("package-examples", pkgContext, filepath.Join(pkgExamplesDir, "README.md")); err != nil {
+			return fmt.Errorf("failed to generate examples for package %s: %w", pkg.Name, err)
+		}
+
+		for _, example := range pkg.Examples {
+			if !example.WholeFile {
+				continue
+			}
+
+			exampleContext := &templates.ExampleContext{
+				PackageContext: pkgContext,
+				Example:        example,
+			}
+
+			examplePath := filepath.Join(pkgExamplesDir, example.Slug()+".md")
+			if err := g.renderToFile("example-file", exampleContext, examplePath); err != nil {
+				return fmt.Errorf("failed to generate example %s of package %s: %w", example.Name, pkg.Name, err)
+			}
+		}
+	}
+
 	// Discover and generate examples from configured directories
 	exampleDirectories, err := g.discoverExampleDirectories()
 	if err != nil {
@@ -240,58 +467,70 @@ func (g *Generator) generateExamplesDocumentation(packages []*discovery.PackageI
 			relPath = filepath.Base(exampleDir)
 		}
 
-		// If this is the main examples directory, process its contents directly
+		directory, err := examples.LoadDirectory(g.files, exampleDir)
+		if err != nil {
+			return err
+		}
+
+		// The main examples directory is covered by the examples index, its
+		// subdirectories get pages of their own and its loose Go files are
+		// standalone programs
 		if relPath == "examples" {
-			// Process contents directly without creating a nested structure
-			entries, err := os.ReadDir(exampleDir)
-			if err != nil {
-				return fmt.Errorf("failed to read examples directory: %w", err)
+			for _, subdirectory := range directory.Subdirectories {
+				if err := g.generateExampleDirectory(subdirectory, filepath.Join(examplesDir, subdirectory.Name), context); err != nil {
+					return err
+				}
 			}
 
-			for _, entry := range entries {
-				name := entry.Name()
-
-				// Skip config files
-				if strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml") {
+			for _, source := range directory.Sources {
+				if strings.HasSuffix(source.Name, "_test.go") {
 					continue
 				}
-
-				sourcePath := filepath.Join(exampleDir, name)
-				destPath := filepath.Join(examplesDir, name)
-
-				if entry.IsDir() {
-					// Create subdirectory and generate documentation
-					if err := os.MkdirAll(destPath, 0755); err != nil {
-						return fmt.Errorf("failed to create subdirectory %s: %w", destPath, err)
-					}
-
-					// Generate README for subdirectory
-					if err := g.generateExampleDirectoryREADME(sourcePath, destPath, name, context); err != nil {
-						return fmt.Errorf("failed to generate README for subdirectory %s: %w", name, err)
-					}
-
-					// Recursively generate documentation for subdirectory
-					if err := g.generateExampleSubdirectoryDocumentation(sourcePath, destPath, context); err != nil {
-						return fmt.Errorf("failed to generate documentation for subdirectory %s: %w", name, err)
-					}
-				} else if strings.HasSuffix(name, ".go") {
-					// Generate markdown for Go files
-					if err := g.generateExampleFileMarkdown(sourcePath, examplesDir, name); err != nil {
-						return fmt.Errorf("failed to generate markdown for %s: %w", name, err)
-					}
+				if err := g.generateExampleFileMarkdown(filepath.Join(exampleDir, source.Name), examplesDir, source.Name); err != nil {
+					return fmt.Errorf("failed to generate markdown for %s: %w", source.Name, err)
 				}
 			}
-		} else {
-			// For other directories, create a subdirectory
-			if err := g.generateExampleDirectoryDocumentation(exampleDir, examplesDir, context); err != nil {
-				return fmt.Errorf("failed to generate examples for directory %s: %w", exampleDir, err)
-			}
+		} else if err := g.generateExampleDirectory(directory, filepath.Join(examplesDir, relPath), context); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// verifyExamples runs the Example functions of every package. Their output
+// comments are what the documentation shows, so a mismatch is an error.
+func (g *Generator) verifyExamples(packages []*discovery.PackageInfo) error {
+	for _, pkg := range packages {
+		var names []string
+		for _, example := range pkg.Examples {
+			if example.Output != "" || example.EmptyOutput {
+				names = append(names, "Example"+example.Name)
+			}
+		}
+
+		if err := g.runner.VerifyExamples(pkg.Path, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// programOutput returns the output of the example program in dir, running it
+// once per generation
+func (g *Generator) programOutput(dir string) (string, error) {
+	if output, ok := g.programOutputs[dir]; ok {
+		return output, nil
+	}
+
+	output, err := g.runner.RunProgram(dir)
+	if err != nil {
+		return "", err
+	}
+	g.programOutputs[dir] = output
+	return output, nil
+}
+
 // discoverExampleDirectories discovers example directories based on configuration
 func (g *Generator) discoverExampleDirectories() ([]string, error) {
 	var directories []string
@@ -305,7 +544,7 @@ func (g *Generator) discoverExampleDirectories() ([]string, error) {
 		}
 
 		// Check if directory exists
-		if _, err := os.Stat(examplePath); os.IsNotExist(err) {
+		if !g.files.Exists(examplePath) {
 			continue // Skip non-existent directories
 		}
 
@@ -323,7 +562,7 @@ func (g *Generator) discoverExampleDirectories() ([]string, error) {
 		}
 
 		for _, pattern := range commonPatterns {
-			if _, err := os.Stat(pattern); err == nil {
+			if g.files.Exists(pattern) {
 				directories = append(directories, pattern)
 			}
 		}
@@ -331,7 +570,7 @@ func (g *Generator) discoverExampleDirectories() ([]string, error) {
 		// Look for examples in package directories
 		for _, pkg := range g.config.Discovery.Packages.ManualPackages {
 			pkgExamplesDir := filepath.Join(g.projectPath, pkg.Path, "examples")
-			if _, err := os.Stat(pkgExamplesDir); err == nil {
+			if g.files.Exists(pkgExamplesDir) {
 				directories = append(directories, pkgExamplesDir)
 			}
 		}
@@ -340,110 +579,40 @@ func (g *Generator) discoverExampleDirectories() ([]string, error) {
 	return directories, nil
 }
 
-// generateExampleDirectoryDocumentation generates documentation for a single example directory
-func (g *Generator) generateExampleDirectoryDocumentation(sourceDir, outputBaseDir string, context *templates.Context) error {
-	// Get relative path from project root for naming
-	relPath, err := filepath.Rel(g.projectPath, sourceDir)
-	if err != nil {
-		relPath = filepath.Base(sourceDir)
+// generateExampleDirectory renders the page of an example directory and its
+// subdirectories. Images are copied so the README can show them.
+func (g *Generator) generateExampleDirectory(directory *examples.Directory, outputDir string, context *templates.Context) error {
+	dirContext := &templates.ExampleDirectoryContext{
+		Context:   context,
+		Directory: directory,
 	}
 
-	// Create output directory
-	outputDir := filepath.Join(outputBaseDir, relPath)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
-	}
-
-	// Generate README for this example directory
-	if err := g.generateExampleDirectoryREADME(sourceDir, outputDir, relPath, context); err != nil {
-		return fmt.Errorf("failed to generate README for %s: %w", sourceDir, err)
+	// Programs are run for their real output when verifying examples
+	if g.runner != nil && directory.Program {
+		output, err := g.programOutput(directory.Dir)
+		if err != nil {
+			return err
+		}
+		dirContext.Output, dirContext.Verified = output, true
 	}
 
-	// Generate markdown documentation for subdirectories and files
-	if err := g.generateExampleSubdirectoryDocumentation(sourceDir, outputDir, context); err != nil {
-		return fmt.Errorf("failed to generate example documentation from %s: %w", sourceDir, err)
+	if err := g.renderToFile("example-directory", dirContext, filepath.Join(outputDir, "README.md")); err != nil {
+		return fmt.Errorf("failed to generate example page for %s: %w", directory.Path, err)
 	}
 
-	return nil
-}
-
-// generateExampleDirectoryREADME generates a README for an example directory
-func (g *Generator) generateExampleDirectoryREADME(sourceDir, outputDir, relPath string, context *templates.Context) error {
-	// For now, generate a simple README
-	// TODO: Create a proper template for example directories
-	readmeContent := fmt.Sprintf(`# Examples: %s
-
-This directory contains examples from: `+sourceDir+`
-
-## Files
-
-`, relPath)
-
-	// List files in the example directory
-	entries, err := os.ReadDir(sourceDir)
-	if err != nil {
-		return fmt.Errorf("failed to read example directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		name := entry.Name()
-
-		// Skip config files and only list Go files and directories
-		if !entry.IsDir() && !strings.HasSuffix(name, ".go") {
-			continue
+	for _, asset := range directory.Assets {
+		data, err := g.files.ReadFile(filepath.Join(directory.Dir, asset))
+		if err != nil {
+			return fmt.Errorf("failed to read example asset %s: %w", asset, err)
 		}
-
-		// For Go files, link to the markdown version
-		if strings.HasSuffix(name, ".go") {
-			markdownName := strings.TrimSuffix(name, ".go") + ".md"
-			readmeContent += fmt.Sprintf("- [%s](%s)\n", name, markdownName)
-		} else {
-			readmeContent += fmt.Sprintf("- [%s](%s)\n", name, name)
+		if err := g.writeFile(filepath.Join(outputDir, asset), data); err != nil {
+			return err
 		}
 	}
 
-	readmePath := filepath.Join(outputDir, "README.md")
-	return os.WriteFile(readmePath, []byte(readmeContent), 0644)
-}
-
-// generateExampleSubdirectoryDocumentation generates markdown documentation for example subdirectories
-func (g *Generator) generateExampleSubdirectoryDocumentation(sourceDir, outputDir string, context *templates.Context) error {
-	entries, err := os.ReadDir(sourceDir)
-	if err != nil {
-		return fmt.Errorf("failed to read source directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		name := entry.Name()
-
-		// Skip config files
-		if strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml") {
-			continue
-		}
-
-		sourcePath := filepath.Join(sourceDir, name)
-		destPath := filepath.Join(outputDir, name)
-
-		if entry.IsDir() {
-			// Create subdirectory and generate documentation
-			if err := os.MkdirAll(destPath, 0755); err != nil {
-				return fmt.Errorf("failed to create subdirectory %s: %w", destPath, err)
-			}
-
-			// Generate README for subdirectory
-			if err := g.generateExampleDirectoryREADME(sourcePath, destPath, name, context); err != nil {
-				return fmt.Errorf("failed to generate README for subdirectory %s: %w", name, err)
-			}
-
-			// Recursively generate documentation for subdirectory
-			if err := g.generateExampleSubdirectoryDocumentation(sourcePath, destPath, context); err != nil {
-				return fmt.Errorf("failed to generate documentation for subdirectory %s: %w", name, err)
-			}
-		} else if strings.HasSuffix(name, ".go") {
-			// Generate markdown for Go files
-			if err := g.generateExampleFileMarkdown(sourcePath, outputDir, name); err != nil {
-				return fmt.Errorf("failed to generate markdown for %s: %w", name, err)
-			}
+	for _, subdirectory := range directory.Subdirectories {
+		if err := g.generateExampleDirectory(subdirectory, filepath.Join(outputDir, subdirectory.Name), context); err != nil {
+			return err
 		}
 	}
 
@@ -453,7 +622,7 @@ func (g *Generator) generateExampleSubdirectoryDocumentation(sourceDir, outputDi
 // generateExampleFileMarkdown generates markdown documentation for a single example file
 func (g *Generator) generateExampleFileMarkdown(sourcePath, outputDir, fileName string) error {
 	// Read the source file
-	content, err := os.ReadFile(sourcePath)
+	content, err := g.files.ReadFile(sourcePath)
 	if err != nil {
 		return fmt.Errorf("failed to read source file %s: %w", sourcePath, err)
 	}
@@ -462,10 +631,27 @@ func (g *Generator) generateExampleFileMarkdown(sourcePath, outputDir, fileName
 	markdownName := strings.TrimSuffix(fileName, ".go") + ".md"
 	markdownPath := filepath.Join(outputDir, markdownName)
 
+	// The package doc comment describes the example, programs are run for
+	// their real output when verifying examples
+	description := "This example demonstrates basic usage of the library."
+	var output string
+	if file, err := parser.ParseFile(token.NewFileSet(), sourcePath, content, parser.ParseComments); err == nil {
+		if file.Doc != nil {
+			description = strings.TrimSpace(file.Doc.Text())
+		}
+		if g.runner != nil && examples.IsMainProgram(file) {
+			stdout, err := g.programOutput(filepath.Dir(sourcePath))
+			if err != nil {
+				return err
+			}
+			output = "\n## Output\n\n```\n" + strings.TrimRight(stdout, "\n") + "\n```\n"
+		}
+	}
+
 	// Generate markdown content
 	markdownContent := fmt.Sprintf(`# %s
 
-This example demonstrates basic usage of the library.
+%s
 
 ## Source Code
 
@@ -481,21 +667,17 @@ To run this example:
 cd %s
 go run %s
 `+"```"+`
-
-## Expected Output
-
-`+"```"+`
-Hello from Proton examples!
-`+"```"+`
-`,
+%s`,
 		strings.TrimSuffix(fileName, ".go"),
+		description,
 		string(content),
 		filepath.Base(outputDir),
 		fileName,
+		output,
 	)
 
 	// Write markdown file
-	return os.WriteFile(markdownPath, []byte(markdownContent), 0644)
+	return g.writeFile(markdownPath, []byte(markdownContent))
 }
 
 // generateGuidesDocumentation generates guides documentation
@@ -508,7 +690,7 @@ func (g *Generator) generateGuidesDocumentation(context *templates.Context) erro
 
 	// Generate guides index
 	guidesIndexPath := filepath.Join(guidesDir, "README.md")
-	if err := g.templates.RenderToFile("guides-index", context, guidesIndexPath); err != nil {
+	if err := g.renderToFile("guides-index", context, guidesIndexPath); err != nil {
 		return fmt.Errorf("failed to generate guides index: %w", err)
 	}
 
@@ -520,14 +702,14 @@ func (g *Generator) generateGuidesDocumentation(context *templates.Context) erro
 		}
 
 		// Create package-specific guides directory
-		pkgGuidesDir := filepath.Join(guidesDir, pkg.Name)
+		pkgGuidesDir := filepath.Join(guidesDir, pkg.Slug)
 		if err := os.MkdirAll(pkgGuidesDir, 0755); err != nil {
 			return fmt.Errorf("failed to create guides directory for package %s: %w", pkg.Name, err)
 		}
 
 		// Generate package-specific best practices
 		bestPracticesPath := filepath.Join(pkgGuidesDir, "best-practices.md")
-		if err := g.templates.RenderToFile("package-best-practices", pkgContext, bestPracticesPath); err != nil {
+		if err := g.renderToFile("package-best-practices", pkgContext, bestPracticesPath); err != nil {
 			return fmt.Errorf("failed to generate best practices for package %s: %w", pkg.Name, err)
 		}
 	}
@@ -535,14 +717,14 @@ func (g *Generator) generateGuidesDocumentation(context *templates.Context) erro
 	// Generate global guides if enabled
 	if g.config.Discovery.Guides.IncludeContributing {
 		contributingPath := filepath.Join(guidesDir, "contributing.md")
-		if err := g.templates.RenderToFile("contributing", context, contributingPath); err != nil {
+		if err := g.renderToFile("contributing", context, contributingPath); err != nil {
 			return fmt.Errorf("failed to generate contributing guide: %w", err)
 		}
 	}
 
 	if g.config.Discovery.Guides.IncludeFAQ {
 		faqPath := filepath.Join(guidesDir, "faq.md")
-		if err := g.templates.RenderToFile("faq", context, faqPath); err != nil {
+		if err := g.renderToFile("faq", context, faqPath); err != nil {
 			return fmt.Errorf("failed to generate FAQ: %w", err)
 		}
 	}
@@ -550,7 +732,7 @@ func (g *Generator) generateGuidesDocumentation(context *templates.Context) erro
 	// Generate custom guides
 	for _, guide := range g.config.Discovery.Guides.CustomGuides {
 		guidePath := filepath.Join(guidesDir, fmt.Sprintf("%s.md", guide.Name))
-		if err := g.templates.RenderToFile(guide.Name, context, guidePath); err != nil {
+		if err := g.renderToFile(guide.Name, context, guidePath); err != nil {
 			return fmt.Errorf("failed to generate custom guide %s: %w", guide.Name, err)
 		}
 	}
@@ -561,13 +743,13 @@ func (g *Generator) generateGuidesDocumentation(context *templates.Context) erro
 // generateGitBookConfig generates the .gitbook.yml configuration file
 func (g *Generator) generateGitBookConfig(context *templates.Context) error {
 	configPath := filepath.Join(g.outputPath, ".gitbook.yml")
-	if err := g.templates.RenderToFile("gitbook-config", context, configPath); err != nil {
+	if err := g.renderToFile("gitbook-config", context, configPath); err != nil {
 		return fmt.Errorf("failed to generate GitBook configuration: %w", err)
 	}
 
 	// Also generate SUMMARY.md for GitBook navigation
 	summaryPath := filepath.Join(g.outputPath, "SUMMARY.md")
-	if err := g.templates.RenderToFile("gitbook-summary", context, summaryPath); err != nil {
+	if err := g.renderToFile("gitbook-summary", context, summaryPath); err != nil {
 		return fmt.Errorf("failed to generate GitBook summary: %w", err)
 	}
 
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// renderToFile renders a template and writes the result to an output file
+func (g *Generator) renderToFile(templateName string, data interface{}, outputPath string) error {
+	content, err := g.templates.RenderToString(templateName, data)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(outputPath, []byte(content))
+}
+
+// writeFile writes an output file and records it as produced by this run.
+// Files whose content is unchanged are not rewritten, so their modification
+// times stay the same across runs.
+func (g *Generator) writeFile(path string, content []byte) error {
+	g.written[filepath.Clean(path)] = true
+
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// cleanOutputDirectory removes every file from the output directory that was
+// not produced by this run, along with directories left empty
+func (g *Generator) cleanOutputDirectory() error {
+	var dirs []string
+
+	err := filepath.WalkDir(g.outputPath, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != g.outputPath {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if g.written[filepath.Clean(path)] {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+
+	// Remove the deepest directories first so their parents can become empty
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, dir := range dirs {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			if err := os.Remove(dir); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", dir, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/discovery"
+	"github.com/kolosys/proton/internal/gitfs"
+	"github.com/kolosys/proton/internal/projectfs"
+	"github.com/kolosys/proton/internal/templates"
+)
+
+// latestDir is the directory the most recent release is published under
+// along with its own
+const latestDir = "latest"
+
+// versionDocs is a version selected for documentation along with the
+// packages discovered in it
+type versionDocs struct {
+	version    *templates.Version
+	config     *config.Config
+	fsys       *gitfs.FS
+	discoverer *discovery.Discoverer
+	packages   []*discovery.PackageInfo
+}
+
+// discoverVersions selects the versions to document and discovers their
+// packages, reading the sources from git. The returned versions must be
+// closed, also on errors.
+func (g *Generator) discoverVersions() ([]*versionDocs, error) {
+	refs, err := g.selectVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Examples of past versions can't be run
+	cfg := *g.config
+	cfg.Discovery.Examples.Verify = false
+
+	var versions []*versionDocs
+	for _, ref := range refs {
+		fsys, err := gitfs.New(g.projectPath, ref)
+		if err != nil {
+			return versions, fmt.Errorf("failed to read %s: %w", ref, err)
+		}
+
+		docs := &versionDocs{
+			version: &templates.Version{
+				Name:    ref,
+				Dir:     strings.ReplaceAll(ref, "/", "-"),
+				Commit:  fsys.Commit(),
+				Release: isRelease(ref),
+				Branch:  ref == g.config.Versions.Branch,
+			},
+			config:     &cfg,
+			fsys:       fsys,
+			discoverer: discovery.NewFromFS(&cfg, g.projectPath, fsys),
+		}
+		versions = append(versions, docs)
+
+		if docs.version.Dir == latestDir {
+			return versions, fmt.Errorf("%s can't be documented, its directory is reserved for the latest release", ref)
+		}
+
+		docs.packages, err = docs.discoverer.DiscoverPackages()
+		if err != nil {
+			return versions, fmt.Errorf("package discovery of %s failed: %w", ref, err)
+		}
+		docs.version.Index = docs.discoverer.SymbolIndex()
+	}
+
+	if latest := latestVersion(versions); latest != nil {
+		latest.version.Latest = true
+	}
+	return versions, nil
+}
+
+// selectVersions returns the configured branch, the most recent release tags
+// from the newest, then further configured tags
+func (g *Generator) selectVersions() ([]string, error) {
+	cfg := g.config.Versions
+
+	tags, err := gitfs.Tags(g.projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var releases []string
+	for _, tag := range tags {
+		if isRelease(tag) {
+			releases = append(releases, tag)
+		}
+	}
+	sortReleases(releases)
+	if len(releases) > cfg.Latest {
+		releases = releases[:cfg.Latest]
+	}
+
+	// Configured tags that are releases sort along with the others
+	selected := make(map[string]bool)
+	var others []string
+	for _, tag := range cfg.Tags {
+		switch {
+		case containsTag(releases, tag) || selected[tag]:
+		case isRelease(tag):
+			releases = append(releases, tag)
+		default:
+			others = append(others, tag)
+		}
+		selected[tag] = true
+	}
+	sortReleases(releases)
+
+	var refs []string
+	if cfg.Branch != "" {
+		refs = append(refs, cfg.Branch)
+	}
+	refs = append(refs, releases...)
+	refs = append(refs, others...)
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no versions to document: the repository has no release tags and versions.branch is empty")
+	}
+	return refs, nil
+}
+
+// setVersions lets the pages of the work tree link to the versions
+func (g *Generator) setVersions(versions []*versionDocs) {
+	for _, docs := range versions {
+		g.versions = append(g.versions, docs.version)
+	}
+	g.templates.SetVersions(g.versions, nil, "../"+path.Clean(filepath.ToSlash(g.config.Versions.Directory))+"/")
+}
+
+// generateVersions generates the documentation of every version in a
+// directory of its own, the most recent release once more as "latest", and
+// an index of the versions
+func (g *Generator) generateVersions(versions []*versionDocs) error {
+	versionsDir := filepath.Join(g.outputPath, g.config.Versions.Directory)
+
+	for _, docs := range versions {
+		if err := g.generateVersion(docs, filepath.Join(versionsDir, docs.version.Dir)); err != nil {
+			return fmt.Errorf("failed to generate documentation of %s: %w", docs.version.Name, err)
+		}
+	}
+
+	latest := latestVersion(versions)
+	if latest != nil {
+		if err := g.generateVersion(latest, filepath.Join(versionsDir, latestDir)); err != nil {
+			return fmt.Errorf("failed to generate documentation of %s as latest: %w", latest.version.Name, err)
+		}
+	}
+
+	indexContext := &templates.VersionsContext{
+		Context: &templates.Context{
+			Repository: g.config.Repository,
+			Config:     g.config,
+			Metadata:   g.config.Metadata,
+			Versions:   g.versions,
+		},
+		Home: strings.Repeat("../", strings.Count(path.Clean(filepath.ToSlash(g.config.Versions.Directory)), "/")+1) + "README.md",
+	}
+	if latest != nil {
+		indexContext.Latest = latest.version
+	}
+
+	if err := g.renderToFile("versions-index", indexContext, filepath.Join(versionsDir, "README.md")); err != nil {
+		return fmt.Errorf("failed to generate versions index: %w", err)
+	}
+	return nil
+}
+
+// generateVersion renders the documentation of a version into outputPath.
+// Its pages link to the same symbols in the other versions.
+func (g *Generator) generateVersion(docs *versionDocs, outputPath string) error {
+	engine, err := templates.New(docs.config, g.projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to create template engine: %w", err)
+	}
+	engine.SetSymbolIndex(docs.version.Index)
+	engine.SetVersions(g.versions, docs.version, "../../")
+
+	version := &Generator{
+		config:      docs.config,
+		projectPath: g.projectPath,
+		outputPath:  outputPath,
+		discoverer:  docs.discoverer,
+		templates:   engine,
+		written:     g.written,
+		files:       projectfs.New(g.projectPath, docs.fsys),
+		version:     docs.version,
+	}
+	return version.render(docs.packages)
+}
+
+// closeVersions stops the git processes reading the versions
+func closeVersions(versions []*versionDocs) {
+	for _, docs := range versions {
+		docs.fsys.Close()
+	}
+}
+
+// latestVersion returns the most recent release, or the first version if none
+// is a release
+func latestVersion(versions []*versionDocs) *versionDocs {
+	var latest *versionDocs
+	for _, docs := range versions {
+		if !docs.version.Release {
+			continue
+		}
+		if latest == nil || compareReleases(docs.version.Name, latest.version.Name) > 0 {
+			latest = docs
+		}
+	}
+	if latest == nil && len(versions) > 0 {
+		return versions[0]
+	}
+	return latest
+}
+
+// isRelease reports whether a tag names a release, vMAJOR.MINOR.PATCH
+// without pre-release or build suffix
+func isRelease(tag string) bool {
+	_, ok := parseRelease(tag)
+	return ok
+}
+
+// parseRelease parses the version numbers of a release tag
+func parseRelease(tag string) ([3]int, bool) {
+	var numbers [3]int
+	parts := strings.Split(strings.TrimPrefix(tag, "v"), ".")
+	if !strings.HasPrefix(tag, "v") || len(parts) != 3 {
+		return numbers, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part != strconv.Itoa(n) {
+			return numbers, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
+
+// compareReleases compares two release tags by precedence
+func compareReleases(a, b string) int {
+	x, _ := parseRelease(a)
+	y, _ := parseRelease(b)
+	for i := range x {
+		if x[i] != y[i] {
+			return x[i] - y[i]
+		}
+	}
+	return 0
+}
+
+// sortReleases sorts release tags from the newest
+func sortReleases(tags []string) {
+	sort.Slice(tags, func(i, j int) bool {
+		return compareReleases(tags[i], tags[j]) > 0
+	})
+}
+
+// containsTag reports whether tags contains tag
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,344 @@
+// Package gitfs reads the tree of a git commit as an fs.FS, straight from the
+// object store of a local repository without checking the commit out.
+package gitfs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS is the tree of a commit, or of a directory within it. Listings are read
+// when the FS is created, file contents on demand through a single git
+// cat-file process. FS is safe for concurrent use.
+type FS struct {
+	dir     string // Directory the git commands run in
+	commit  string
+	modTime time.Time // Commit time, reported as the modification time of every file
+	files   map[string]*blob
+	dirs    map[string][]fs.DirEntry
+
+	mu  sync.Mutex
+	cat *catFile // Started by the first read, nil after Close
+}
+
+// blob is a file of the tree
+type blob struct {
+	object string
+	info   *fileInfo
+}
+
+// New reads the tree of ref for the directory dir of a repository. Paths of
+// the FS are relative to dir, so for a subdirectory of the work tree only
+// that subdirectory of the commit is visible. Submodules and symbolic links
+// are left out. The FS must be closed to stop its git process.
+func New(dir, ref string) (*FS, error) {
+	commit, err := git(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %q", ref)
+	}
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not inside a git work tree: %w", dir, err)
+	}
+	committed, err := git(dir, "show", "-s", "--format=%ct", commit)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FS{
+		dir:    dir,
+		commit: commit,
+		files:  make(map[string]*blob),
+		dirs:   make(map[string][]fs.DirEntry),
+	}
+	if seconds, err := strconv.ParseInt(committed, 10, 64); err == nil {
+		f.modTime = time.Unix(seconds, 0)
+	}
+
+	listing, err := git(dir, "ls-tree", "-r", "-z", "-l", "--full-tree", commit+":"+strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s at %s: %w", dir, ref, err)
+	}
+	if err := f.parseListing(listing); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Commit returns the full hash of the commit the FS reads
+func (f *FS) Commit() string {
+	return f.commit
+}
+
+// Tags lists the tags of the repository containing dir
+func Tags(dir string) ([]string, error) {
+	output, err := git(dir, "tag", "--list")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// parseListing adds the entries of "git ls-tree -r -z -l" output, lines of
+// the form "<mode> <type> <object> <size>\t<path>", along with the
+// directories containing them
+func (f *FS) parseListing(listing string) error {
+	f.dirs["."] = nil
+	for _, line := range strings.Split(listing, "\x00") {
+		if line == "" {
+			continue
+		}
+		meta, name, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 {
+			return fmt.Errorf("unexpected ls-tree output %q", line)
+		}
+		if fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("unexpected ls-tree output %q", line)
+		}
+		mode := fs.FileMode(0644)
+		if fields[0] == "100755" {
+			mode = 0755
+		}
+
+		info := &fileInfo{name: path.Base(name), size: size, mode: mode, modTime: f.modTime}
+		f.files[name] = &blob{object: fields[2], info: info}
+		f.addEntry(path.Dir(name), info)
+	}
+
+	for _, entries := range f.dirs {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Name() < entries[j].Name()
+		})
+	}
+	return nil
+}
+
+// addEntry adds an entry to the listing of dir, creating dir and its parents
+// as needed
+func (f *FS) addEntry(dir string, info *fileInfo) {
+	_, exists := f.dirs[dir]
+	f.dirs[dir] = append(f.dirs[dir], fs.FileInfoToDirEntry(info))
+	if !exists && dir != "." {
+		f.addEntry(path.Dir(dir), &fileInfo{name: path.Base(dir), mode: fs.ModeDir | 0755, modTime: f.modTime})
+	}
+}
+
+// Open implements fs.FS
+func (f *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if entries, ok := f.dirs[name]; ok {
+		return &dirFile{info: f.dirInfo(name), entries: entries}, nil
+	}
+
+	data, err := f.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &file{info: f.files[name].info, Reader: bytes.NewReader(data)}, nil
+}
+
+// ReadFile implements fs.ReadFileFS
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	b, ok := f.files[name]
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cat == nil {
+		cat, err := startCatFile(f.dir)
+		if err != nil {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		f.cat = cat
+	}
+
+	data, err := f.cat.read(b.object)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// ReadDir implements fs.ReadDirFS
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, ok := f.dirs[name]
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+// Stat implements fs.StatFS
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	if _, ok := f.dirs[name]; ok {
+		return f.dirInfo(name), nil
+	}
+	if b, ok := f.files[name]; ok {
+		return b.info, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// Close stops the git process reading file contents
+func (f *FS) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cat == nil {
+		return nil
+	}
+	err := f.cat.close()
+	f.cat = nil
+	return err
+}
+
+// dirInfo returns the file info of a directory of the tree
+func (f *FS) dirInfo(name string) *fileInfo {
+	return &fileInfo{name: path.Base(name), mode: fs.ModeDir | 0755, modTime: f.modTime}
+}
+
+// catFile reads objects through "git cat-file --batch"
+type catFile struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// startCatFile starts a cat-file process for the repository of dir
+func startCatFile(dir string) (*catFile, error) {
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Dir = dir
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start git cat-file: %w", err)
+	}
+	return &catFile{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+// read returns the contents of an object. The batch output of an object is a
+// "<object> <type> <size>" header line followed by the contents and a newline.
+func (c *catFile) read(object string) ([]byte, error) {
+	if _, err := fmt.Fprintln(c.stdin, object); err != nil {
+		return nil, fmt.Errorf("failed to request object %s: %w", object, err)
+	}
+
+	header, err := c.stdout.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", object, err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("failed to read object %s: %s", object, strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: unexpected header %q", object, header)
+	}
+
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(c.stdout, data); err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", object, err)
+	}
+	return data[:size], nil
+}
+
+// close ends the process by closing its input
+func (c *catFile) close() error {
+	c.stdin.Close()
+	return c.cmd.Wait()
+}
+
+// git runs a git command in dir and returns its trimmed output
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// fileInfo describes a file or directory of the tree
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) Mode() fs.FileMode  { return i.mode }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *fileInfo) Sys() any           { return nil }
+
+// file is an open file of the tree
+type file struct {
+	info *fileInfo
+	*bytes.Reader
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+// dirFile is an open directory of the tree
+type dirFile struct {
+	info    *fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dirFile) Close() error               { return nil }
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
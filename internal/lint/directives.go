@@ -0,0 +1,76 @@
+package lint
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Suppression directives. "//proton:ignore [rule,...] [reason]" suppresses
+// the diagnostics of the declaration it documents or trails, and
+// "//proton:ignore-file [rule,...]" those of the whole file. Without rules
+// they suppress every rule.
+const (
+	ignoreDirective     = "//proton:ignore"
+	ignoreFileDirective = "//proton:ignore-file"
+)
+
+// directives are the suppression directives of a file
+type directives struct {
+	file  map[string]bool         // Rules ignored in the whole file, "" for all
+	lines map[int]map[string]bool // Rules ignored by the declaration on a line
+}
+
+// parseDirectives collects the directives of a file. A directive inside a
+// doc comment applies to the line the comment documents, which go doc leaves
+// out of the documentation.
+func parseDirectives(fileSet *token.FileSet, file *ast.File) *directives {
+	d := &directives{file: make(map[string]bool), lines: make(map[int]map[string]bool)}
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if rules, ok := directiveRules(comment.Text, ignoreFileDirective); ok {
+				for _, rule := range rules {
+					d.file[rule] = true
+				}
+				continue
+			}
+			rules, ok := directiveRules(comment.Text, ignoreDirective)
+			if !ok {
+				continue
+			}
+
+			// Directives apply to their own line and to the line following
+			// their comment group, the declaration it documents
+			lines := []int{fileSet.Position(comment.Pos()).Line, fileSet.Position(group.End()).Line + 1}
+			for _, line := range lines {
+				if d.lines[line] == nil {
+					d.lines[line] = make(map[string]bool)
+				}
+				for _, rule := range rules {
+					d.lines[line][rule] = true
+				}
+			}
+		}
+	}
+	return d
+}
+
+// directiveRules parses the rules of a directive, returning [""] for a
+// directive without rules
+func directiveRules(text, directive string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(text, directive)
+	if !ok || rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return nil, false
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || Rules[strings.Split(fields[0], ",")[0]] == "" {
+		return []string{""}, true
+	}
+	return strings.Split(fields[0], ","), true
+}
+
+// ignores reports whether a directive suppresses a rule on a line
+func (d *directives) ignores(rule string, line int) bool {
+	return d.file[""] || d.file[rule] || d.lines[line][""] || d.lines[line][rule]
+}
@@ -0,0 +1,375 @@
+// Package lint checks the doc comments of Go source files for the problems
+// that make generated documentation incomplete or wrong, e.g. "Parameters:"
+// sections naming parameters the function doesn't have.
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rule IDs, reported with every diagnostic and accepted by //proton:ignore
+const (
+	RuleMissingDoc        = "missing-doc"
+	RuleDocName           = "doc-name"
+	RuleUnknownParam      = "unknown-param"
+	RuleUndocumentedParam = "undocumented-param"
+	RuleReturnCount       = "return-count"
+	RuleDeprecatedFormat  = "deprecated-format"
+)
+
+// Rules describes the rules by ID
+var Rules = map[string]string{
+	RuleMissingDoc:        "exported symbol without a doc comment",
+	RuleDocName:           "doc comment not starting with the name of its symbol",
+	RuleUnknownParam:      "Parameters: section documenting a parameter the function doesn't have",
+	RuleUndocumentedParam: "Parameters: section leaving out a parameter of the function",
+	RuleReturnCount:       "Returns: section listing more or fewer results than the function returns",
+	RuleDeprecatedFormat:  "deprecation note that go doc and Proton don't recognize",
+}
+
+// Diagnostic is a problem found in a doc comment
+type Diagnostic struct {
+	Position token.Position
+	Rule     string
+	Message  string
+}
+
+// String formats the diagnostic as "file:line:column: message (rule)"
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s (%s)", d.Position, d.Message, d.Rule)
+}
+
+// Linter checks the files of Go packages
+type Linter struct {
+	fileSet *token.FileSet
+}
+
+// New creates a linter
+func New() *Linter {
+	return &Linter{fileSet: token.NewFileSet()}
+}
+
+// LintPackage checks the files of a single package. Generated files are
+// skipped. Diagnostics suppressed by //proton:ignore directives are left out,
+// the others are ordered by position.
+func (l *Linter) LintPackage(paths []string) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	for _, path := range paths {
+		file, err := parser.ParseFile(l.fileSet, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+
+		checker := &fileChecker{fileSet: l.fileSet, file: file, directives: parseDirectives(l.fileSet, file)}
+		checker.check()
+		diagnostics = append(diagnostics, checker.diagnostics...)
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Position, diagnostics[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return diagnostics, nil
+}
+
+// fileChecker collects the diagnostics of a file
+type fileChecker struct {
+	fileSet     *token.FileSet
+	file        *ast.File
+	directives  *directives
+	diagnostics []Diagnostic
+}
+
+// check checks the package clause and the declarations of the file
+func (c *fileChecker) check() {
+	if c.file.Doc != nil {
+		c.checkDeprecation(c.file.Name, c.file.Doc)
+	}
+
+	for _, decl := range c.file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			c.checkFunc(decl)
+		case *ast.GenDecl:
+			c.checkGenDecl(decl)
+		}
+	}
+}
+
+// checkFunc checks the doc comment of a function or method
+func (c *fileChecker) checkFunc(decl *ast.FuncDecl) {
+	if !decl.Name.IsExported() || decl.Recv != nil && !exportedReceiver(decl.Recv) {
+		return
+	}
+
+	kind := "function"
+	if decl.Recv != nil {
+		kind = "method"
+	}
+	if !c.checkDoc(decl.Name, decl.Doc, kind) {
+		return
+	}
+	c.checkSections(decl)
+}
+
+// checkGenDecl checks the doc comments of the types, constants and variables
+// of a declaration. Specs of a group are documented by the group's comment
+// or their own.
+func (c *fileChecker) checkGenDecl(decl *ast.GenDecl) {
+	kinds := map[token.Token]string{token.TYPE: "type", token.CONST: "constant", token.VAR: "variable"}
+	kind, ok := kinds[decl.Tok]
+	if !ok {
+		return
+	}
+
+	for _, spec := range decl.Specs {
+		var names []*ast.Ident
+		var doc *ast.CommentGroup
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			names, doc = []*ast.Ident{spec.Name}, spec.Doc
+		case *ast.ValueSpec:
+			names, doc = spec.Names, spec.Doc
+			if doc == nil {
+				doc = spec.Comment
+			}
+		}
+
+		var name *ast.Ident
+		for _, ident := range names {
+			if ident.IsExported() {
+				name = ident
+				break
+			}
+		}
+		if name == nil {
+			continue
+		}
+
+		switch {
+		case doc != nil:
+			// A spec's own comment may describe several names of a group,
+			// so only the comments of single declarations must name them
+			if !decl.Lparen.IsValid() {
+				c.checkDoc(name, doc, kind)
+			} else {
+				c.checkDeprecation(name, doc)
+			}
+		case decl.Doc != nil && decl.Lparen.IsValid():
+			c.checkDeprecation(name, decl.Doc)
+		default:
+			c.checkDoc(name, decl.Doc, kind)
+		}
+	}
+}
+
+// checkDoc reports a missing doc comment or one not starting with the name
+// of its symbol, and malformed deprecation notes. It reports whether the
+// symbol has a doc comment.
+func (c *fileChecker) checkDoc(name *ast.Ident, doc *ast.CommentGroup, kind string) bool {
+	text := ""
+	if doc != nil {
+		text = strings.TrimSpace(doc.Text())
+	}
+	if text == "" {
+		c.report(name, RuleMissingDoc, "exported %s %s has no doc comment", kind, name.Name)
+		return false
+	}
+
+	if !startsWithName(text, name.Name) && !deprecationPrefix.MatchString(text) {
+		first, _, _ := strings.Cut(text, "\n")
+		c.report(name, RuleDocName, "doc comment of %s should start with %q, not %q", name.Name, name.Name+" ...", truncate(first, 40))
+	}
+	c.checkDeprecation(name, doc)
+	return true
+}
+
+// deprecationPrefix matches text meant as a deprecation note in any case or
+// spelling, e.g. "Deprecated: ...", "DEPRECATED: ..." or "Deprecated - ..."
+var deprecationPrefix = regexp.MustCompile(`(?i)^deprecated\s*[:.!-]`)
+
+// checkDeprecation reports deprecation notes that aren't a paragraph
+// starting with "Deprecated: " followed by an explanation
+func (c *fileChecker) checkDeprecation(name *ast.Ident, doc *ast.CommentGroup) {
+	for _, paragraph := range strings.Split(doc.Text(), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+
+		if note, ok := strings.CutPrefix(paragraph, "Deprecated:"); ok {
+			if strings.TrimSpace(note) == "" {
+				c.report(name, RuleDeprecatedFormat, "deprecation note of %s should explain what to use instead", name.Name)
+			}
+			continue
+		}
+
+		if deprecationPrefix.MatchString(paragraph) {
+			c.report(name, RuleDeprecatedFormat, "deprecation note of %s should start with \"Deprecated: \", not %q", name.Name, truncate(paragraph, 20))
+			continue
+		}
+		for _, line := range strings.Split(paragraph, "\n")[1:] {
+			if deprecationPrefix.MatchString(strings.TrimSpace(line)) {
+				c.report(name, RuleDeprecatedFormat, "deprecation note of %s should be a paragraph of its own", name.Name)
+			}
+		}
+	}
+}
+
+// checkSections compares the Parameters: and Returns: sections of a
+// function's doc comment with its signature
+func (c *fileChecker) checkSections(decl *ast.FuncDecl) {
+	sections := parseSections(decl.Doc.Text())
+
+	// Sections without items are prose, e.g. "Returns: nothing useful"
+	if documented := sections["parameters"]; len(documented) > 0 {
+		params := make(map[string]bool)
+		var order []string
+		for _, field := range decl.Type.Params.List {
+			for _, name := range field.Names {
+				if name.Name != "_" {
+					params[name.Name] = true
+					order = append(order, name.Name)
+				}
+			}
+		}
+
+		seen := make(map[string]bool)
+		for _, item := range documented {
+			// Items start with the parameter name, e.g. "- ctx: ..." or "- ctx (context.Context) ..."
+			name, _, _ := strings.Cut(item, ":")
+			if fields := strings.Fields(name); len(fields) > 0 {
+				name = fields[0]
+			}
+			seen[name] = true
+			if !params[name] {
+				c.report(decl.Name, RuleUnknownParam, "Parameters: section of %s documents %s, which is not a parameter", decl.Name.Name, name)
+			}
+		}
+		for _, name := range order {
+			if !seen[name] {
+				c.report(decl.Name, RuleUndocumentedParam, "Parameters: section of %s leaves out parameter %s", decl.Name.Name, name)
+			}
+		}
+	}
+
+	if documented := sections["returns"]; len(documented) > 0 {
+		results := 0
+		if decl.Type.Results != nil {
+			for _, field := range decl.Type.Results.List {
+				results += max(len(field.Names), 1)
+			}
+		}
+		if len(documented) != results {
+			c.report(decl.Name, RuleReturnCount, "Returns: section of %s lists %d results, but it returns %d", decl.Name.Name, len(documented), results)
+		}
+	}
+}
+
+// report adds a diagnostic at the position of a symbol's name unless a
+// directive suppresses it
+func (c *fileChecker) report(name *ast.Ident, rule, format string, args ...any) {
+	position := c.fileSet.Position(name.Pos())
+	if c.directives.ignores(rule, position.Line) {
+		return
+	}
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Position: position,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// parseSections returns the "- " items of the Parameters: and Returns:
+// sections of a doc comment by lowercased section name. Like the
+// documentation generator, sections start at a line beginning with their
+// name and list one item per line starting with "- ". Items indented deeper
+// than the first item of their section belong to a nested list and aren't
+// items of their own. Sections end at the next heading line, e.g. "Example:".
+func parseSections(doc string) map[string][]string {
+	sections := make(map[string][]string)
+	var current string
+	itemIndent := -1
+	for _, line := range strings.Split(doc, "\n") {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+
+		switch {
+		case strings.HasPrefix(lower, "parameters:"):
+			current, itemIndent = "parameters", -1
+		case strings.HasPrefix(lower, "returns:"):
+			current, itemIndent = "returns", -1
+		case current != "" && strings.HasPrefix(line, "- "):
+			if itemIndent < 0 {
+				itemIndent = indent
+			}
+			if indent <= itemIndent {
+				sections[current] = append(sections[current], strings.TrimPrefix(line, "- "))
+			}
+		case strings.HasSuffix(line, ":") && !strings.HasPrefix(line, "- "):
+			current = ""
+		}
+	}
+	return sections
+}
+
+// startsWithName reports whether a doc comment starts with the name of its
+// symbol, optionally after an article, e.g. "A Client is ..."
+func startsWithName(text, name string) bool {
+	for _, article := range []string{"A ", "An ", "The "} {
+		if rest, ok := strings.CutPrefix(text, article); ok && startsWithName(rest, name) {
+			return true
+		}
+	}
+	rest, ok := strings.CutPrefix(text, name)
+	if !ok {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// exportedReceiver reports whether the receiver's base type is exported
+func exportedReceiver(recv *ast.FieldList) bool {
+	if len(recv.List) == 0 {
+		return false
+	}
+	expr := recv.List[0].Type
+	for {
+		switch typ := expr.(type) {
+		case *ast.StarExpr:
+			expr = typ.X
+		case *ast.IndexExpr:
+			expr = typ.X
+		case *ast.IndexListExpr:
+			expr = typ.X
+		case *ast.Ident:
+			return typ.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+// truncate shortens s to n runes for messages
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
@@ -0,0 +1,156 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSections(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want map[string][]string
+	}{
+		{
+			name: "items with colons",
+			doc:  "Load loads.\nParameters:\n- path: The path.\nReturns:\n- error: An error.\n",
+			want: map[string][]string{"parameters": {"path: The path."}, "returns": {"error: An error."}},
+		},
+		{
+			name: "items without colons",
+			doc:  "Count counts.\n\nReturns:\n  - the count\n  - an error\n",
+			want: map[string][]string{"returns": {"the count", "an error"}},
+		},
+		{
+			name: "name and type items",
+			doc:  "Open opens.\n\nParameters:\n  - name (string) the file\n  - flag (int) the mode\n",
+			want: map[string][]string{"parameters": {"name (string) the file", "flag (int) the mode"}},
+		},
+		{
+			name: "nested lists",
+			doc: "Load loads.\nParameters:\n- configPath: The path, searched for in\n  - .proton/config.yml\n  - The project root directory\n\n" +
+				"- projectPath: The project.\nReturns:\n- *Config: The configuration.\n  - error: not an item\n",
+			want: map[string][]string{"parameters": {"configPath: The path, searched for in", "projectPath: The project."}, "returns": {"*Config: The configuration."}},
+		},
+		{
+			name: "heading ends section",
+			doc:  "Run runs.\n\nParameters:\n  - ctx: The context.\n\nExample:\n  - not a parameter\n",
+			want: map[string][]string{"parameters": {"ctx: The context."}},
+		},
+		{
+			name: "prose section",
+			doc:  "Reset resets.\n\nReturns: nothing useful\n",
+			want: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSections(tt.doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSections() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSections(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string // Rules of the expected diagnostics in order
+	}{
+		{
+			name: "matching sections",
+			src: `// Load loads.
+//
+// Parameters:
+//   - configPath: The path, searched for in
+//     - .proton/config.yml
+//     - The project root directory
+//   - projectPath: The project.
+//
+// Returns:
+//   - the configuration
+//     - nil on errors
+//   - an error
+func Load(configPath, projectPath string) (*int, error) { return nil, nil }
+`,
+		},
+		{
+			name: "name and type items",
+			src: `// Open opens.
+//
+// Parameters:
+//   - name (string) the file
+//   - flag (int) the mode
+func Open(name string, flag int) {}
+`,
+		},
+		{
+			name: "unknown and undocumented parameters",
+			src: `// Open opens.
+//
+// Parameters:
+//   - path (string) the file
+func Open(name string) {}
+`,
+			want: []string{RuleUnknownParam, RuleUndocumentedParam},
+		},
+		{
+			name: "return count",
+			src: `// Count counts.
+//
+// Returns:
+//   - the count
+func Count() (int, error) { return 0, nil }
+`,
+			want: []string{RuleReturnCount},
+		},
+		{
+			name: "ignore directive",
+			src: `// Count counts.
+//
+// Returns:
+//   - the count
+//
+//proton:ignore return-count results documented elsewhere
+func Count() (int, error) { return 0, nil }
+`,
+		},
+		{
+			name: "ignore directive for another rule",
+			src: `// Count counts.
+//
+// Returns:
+//   - the count
+//
+//proton:ignore doc-name
+func Count() (int, error) { return 0, nil }
+`,
+			want: []string{RuleReturnCount},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "example.go")
+			if err := os.WriteFile(path, []byte("// Package example is an example.\npackage example\n\n"+tt.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			diagnostics, err := New().LintPackage([]string{path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, diagnostic := range diagnostics {
+				got = append(got, diagnostic.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rules = %q, want %q\n%v", got, tt.want, diagnostics)
+			}
+		})
+	}
+}
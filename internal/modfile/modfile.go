@@ -0,0 +1,159 @@
+// Package modfile reads the parts of go.mod and go.work files that Proton needs
+// to attribute packages to their modules.
+package modfile
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ModulePath returns the module path declared in go.mod data, or "" if none
+func ModulePath(data []byte) string {
+	for _, line := range directiveLines(data) {
+		if rest, ok := cutDirective(line, "module"); ok {
+			return unquote(rest)
+		}
+	}
+	return ""
+}
+
+// GoVersion returns the go directive of go.mod or go.work data, or "" if none
+func GoVersion(data []byte) string {
+	for _, line := range directiveLines(data) {
+		if rest, ok := cutDirective(line, "go"); ok {
+			return unquote(rest)
+		}
+	}
+	return ""
+}
+
+// WorkUses returns the module directories listed by use directives in go.work data
+func WorkUses(data []byte) []string {
+	var dirs []string
+	for _, arg := range blockArgs(data, "use") {
+		dirs = append(dirs, unquote(arg))
+	}
+	return dirs
+}
+
+// Require is a module requirement of a go.mod file
+type Require struct {
+	Path     string
+	Version  string
+	Indirect bool // Marked "// indirect"
+}
+
+// Requires returns the requirements listed by require directives in go.mod
+// data, in file order
+func Requires(data []byte) []Require {
+	var requires []Require
+	inBlock := false
+
+	for _, raw := range strings.Split(string(data), "\n") {
+		line, comment, _ := strings.Cut(raw, "//")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if !inBlock {
+			rest, ok := cutDirective(line, "require")
+			if !ok {
+				continue
+			}
+			if rest == "(" {
+				inBlock = true
+				continue
+			}
+			line = rest
+		} else if line == ")" {
+			inBlock = false
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		requires = append(requires, Require{
+			Path:     unquote(fields[0]),
+			Version:  fields[1],
+			Indirect: strings.HasPrefix(strings.TrimSpace(comment), "indirect"),
+		})
+	}
+
+	return requires
+}
+
+// ReadModulePath reads the module path from the go.mod file in dir
+func ReadModulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	return ModulePath(data), nil
+}
+
+// blockArgs returns the arguments of a directive that may appear either on a
+// single line or as a parenthesized block
+func blockArgs(data []byte, directive string) []string {
+	var args []string
+	inBlock := false
+
+	for _, line := range directiveLines(data) {
+		if inBlock {
+			if line == ")" {
+				inBlock = false
+				continue
+			}
+			args = append(args, line)
+			continue
+		}
+
+		rest, ok := cutDirective(line, directive)
+		if !ok {
+			continue
+		}
+		if rest == "(" {
+			inBlock = true
+			continue
+		}
+		args = append(args, rest)
+	}
+
+	return args
+}
+
+// directiveLines returns the non-empty lines of a mod file with comments removed
+func directiveLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// cutDirective returns the remainder of line if it starts with the directive keyword
+func cutDirective(line, directive string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, directive)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t' && rest[0] != '(') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// unquote removes Go string quoting from a mod file token if present
+func unquote(token string) string {
+	if unquoted, err := strconv.Unquote(token); err == nil {
+		return unquoted
+	}
+	return token
+}
@@ -0,0 +1,118 @@
+// Package projectfs reads the files of a project by the paths they have on
+// disk, either from the work tree or from an fs.FS such as the tree of a git
+// revision.
+package projectfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS reads the files below a project directory. Paths are the ones the files
+// have in the work tree, whichever tree they are read from. FS is safe for
+// concurrent use if its fs.FS is.
+type FS struct {
+	root string
+	fsys fs.FS // Tree read instead of the file system, nil for the work tree
+}
+
+// Dir reads the work tree of the project at root
+func Dir(root string) *FS {
+	return &FS{root: root}
+}
+
+// New reads the project at root from fsys, which is rooted at the project
+// directory
+func New(root string, fsys fs.FS) *FS {
+	return &FS{root: root, fsys: fsys}
+}
+
+// Root returns the project directory
+func (f *FS) Root() string {
+	return f.root
+}
+
+// WorkTree reports whether the files are read from the work tree
+func (f *FS) WorkTree() bool {
+	return f.fsys == nil
+}
+
+// name converts a path below the project directory into a name of fsys
+func (f *FS) name(path string) (string, error) {
+	rel, err := filepath.Rel(f.root, path)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.ToSlash(rel)
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return name, nil
+}
+
+// ReadFile reads a file of the project
+func (f *FS) ReadFile(path string) ([]byte, error) {
+	if f.fsys == nil {
+		return os.ReadFile(path)
+	}
+	name, err := f.name(path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(f.fsys, name)
+}
+
+// ReadDir lists a directory of the project
+func (f *FS) ReadDir(dir string) ([]fs.DirEntry, error) {
+	if f.fsys == nil {
+		return os.ReadDir(dir)
+	}
+	name, err := f.name(dir)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(f.fsys, name)
+}
+
+// Open opens a file of the project, e.g. for build.Context.OpenFile
+func (f *FS) Open(path string) (io.ReadCloser, error) {
+	if f.fsys == nil {
+		return os.Open(path)
+	}
+	name, err := f.name(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.fsys.Open(name)
+}
+
+// Exists reports whether a file or directory of the project exists
+func (f *FS) Exists(path string) bool {
+	if f.fsys == nil {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	name, err := f.name(path)
+	if err != nil {
+		return false
+	}
+	_, err = fs.Stat(f.fsys, name)
+	return err == nil
+}
+
+// WalkDir walks the project like filepath.WalkDir, passing paths below the
+// project directory to fn
+func (f *FS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	if f.fsys == nil {
+		return filepath.WalkDir(root, fn)
+	}
+	name, err := f.name(root)
+	if err != nil {
+		return err
+	}
+	return fs.WalkDir(f.fsys, name, func(name string, entry fs.DirEntry, err error) error {
+		return fn(filepath.Join(f.root, filepath.FromSlash(name)), entry, err)
+	})
+}
@@ -8,8 +8,11 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/kolosys/proton/internal/api"
 	"github.com/kolosys/proton/internal/config"
+	"github.com/kolosys/proton/internal/coverage"
 	"github.com/kolosys/proton/internal/discovery"
+	"github.com/kolosys/proton/internal/examples"
 )
 
 //go:embed builtin/*.md builtin/*.yml
@@ -20,14 +23,26 @@ type Engine struct {
 	config      *config.Config
 	projectPath string
 	templates   map[string]*template.Template
+	index       *discovery.SymbolIndex // Resolves cross-references, may be nil
+
+	// Versions API reference pages link to, see SetVersions
+	versions     []*Version
+	version      *Version
+	versionsRoot string
 }
 
 // Context provides data for template rendering
 type Context struct {
-	Repository config.Repository        `json:"repository"`
-	Packages   []*discovery.PackageInfo `json:"packages"`
-	Config     *config.Config           `json:"config"`
-	Metadata   config.Metadata          `json:"metadata"`
+	Repository config.Repository              `json:"repository"`
+	Packages   []*discovery.PackageInfo       `json:"packages"`
+	Modules    []*discovery.Module            `json:"modules"`
+	Config     *config.Config                 `json:"config"`
+	Metadata   config.Metadata                `json:"metadata"`
+	CLI        *discovery.CLIReference        `json:"cli"`        // Commands of the CLI reference, nil if it isn't generated
+	Operations *discovery.OperationsReference `json:"operations"` // Binaries of the operations reference, nil if it isn't generated
+	Coverage   *coverage.Report               `json:"coverage"`   // Documentation coverage, nil if its page isn't generated
+	Versions   []*Version                     `json:"versions"`   // Versions documented next to the work tree, if any
+	Version    *Version                       `json:"version"`    // Version being documented, nil for the work tree
 }
 
 // PackageContext provides package-specific data for template rendering
@@ -36,6 +51,55 @@ type PackageContext struct {
 	Package *discovery.PackageInfo `json:"package"`
 }
 
+// ConfigReferenceContext provides the configuration reference for template
+// rendering
+type ConfigReferenceContext struct {
+	*Context
+	Reference *discovery.ConfigReference `json:"reference"`
+}
+
+// ExampleContext provides a whole-file example for template rendering
+type ExampleContext struct {
+	*PackageContext
+	Example *discovery.Example `json:"example"`
+}
+
+// ExampleDirectoryContext provides an example directory for template
+// rendering
+type ExampleDirectoryContext struct {
+	*Context
+	Directory *examples.Directory `json:"directory"`
+	Output    string              `json:"output"`   // Output of the program, set if Verified
+	Verified  bool                `json:"verified"` // The program was run while verifying examples
+}
+
+// CLICommandContext provides a command of the CLI reference for template
+// rendering
+type CLICommandContext struct {
+	*Context
+	Command *discovery.CLICommand `json:"command"`
+}
+
+// BinaryContext provides a binary of the operations reference for template
+// rendering
+type BinaryContext struct {
+	*Context
+	Binary *discovery.Binary `json:"binary"`
+}
+
+// APIDiffContext provides the API changes between two revisions for template
+// rendering
+type APIDiffContext struct {
+	*Context
+	Diff *api.Diff `json:"diff"`
+}
+
+// ModuleContext provides module-specific data for template rendering
+type ModuleContext struct {
+	*Context
+	Module *discovery.Module `json:"module"`
+}
+
 // New creates a new template engine
 func New(cfg *config.Config, projectPath string) (*Engine, error) {
 	engine := &Engine{
@@ -76,6 +140,18 @@ func (e *Engine) loadBuiltinTemplates() error {
 		"api-reference",
 		"examples-index",
 		"package-examples",
+		"example-file",
+		"example-directory",
+		"module-index",
+		"deprecated",
+		"coverage",
+		"api-diff",
+		"versions-index",
+		"config-reference",
+		"cli-reference-index",
+		"cli-command",
+		"operations-index",
+		"operations",
 		"guides-index",
 		"contributing",
 		"faq",
@@ -170,6 +246,7 @@ func (e *Engine) templateFuncs() template.FuncMap {
 		"upper":     strings.ToUpper,
 		"title":     strings.Title,
 		"join":      strings.Join,
+		"repeat":    strings.Repeat,
 		"replace":   strings.ReplaceAll,
 		"contains":  strings.Contains,
 		"hasPrefix": strings.HasPrefix,
@@ -185,10 +262,8 @@ func (e *Engine) templateFuncs() template.FuncMap {
 			}
 			return strings.Join(lines, "\n")
 		},
-		"markdown": func(text string) string {
-			// Simple markdown-like formatting for documentation
-			return text
-		},
+		"markdown":       e.markdown,
+		"inlineMarkdown": e.inlineMarkdown,
 		"codeBlock": func(lang, code string) string {
 			return fmt.Sprintf("```%s\n%s\n```", lang, code)
 		},
@@ -220,8 +295,8 @@ func (e *Engine) templateFuncs() template.FuncMap {
 			return len(fn.Results) > 0
 		},
 		"formatFieldName": func(field *discovery.Field) string {
-			if field.Name == "" {
-				return "*" + field.Type // Embedded field
+			if field.Embedded {
+				return field.Name + " (embedded)"
 			}
 			return field.Name
 		},
@@ -231,11 +306,55 @@ func (e *Engine) templateFuncs() template.FuncMap {
 			}
 			return "`" + strings.Trim(tag, "`") + "`"
 		},
-		"typeLink": func(typeName string) string {
-			// Simple type linking - could be enhanced for cross-references
-			return typeName
-		},
+		"deprecatedSymbols": e.DeprecatedSymbols,
+		"listedTypes":       e.listedTypes,
+		"listedFuncs":       e.listedFuncs,
+		"typeLink":          e.typeLink,
+		"symbolLink":        e.symbolLink,
+		"linkDecl":          e.linkDecl,
+		"packageVersions":   e.packageVersions,
+		"symbolVersions":    e.symbolVersions,
+	}
+}
+
+// listed reports whether a symbol belongs in overviews and navigation.
+// Unexported symbols only do with index_unexported.
+func (e *Engine) listed(exported bool) bool {
+	return exported || e.config.Discovery.APIGeneration.IndexUnexported
+}
+
+// listedTypes filters the types listed in overviews and navigation
+func (e *Engine) listedTypes(types []*discovery.EnhancedType) []*discovery.EnhancedType {
+	var listed []*discovery.EnhancedType
+	for _, typ := range types {
+		if e.listed(typ.Exported) {
+			listed = append(listed, typ)
+		}
+	}
+	return listed
+}
+
+// listedFuncs filters the functions listed in overviews and navigation
+func (e *Engine) listedFuncs(funcs []*discovery.EnhancedFunc) []*discovery.EnhancedFunc {
+	var listed []*discovery.EnhancedFunc
+	for _, fn := range funcs {
+		if e.listed(fn.Exported) {
+			listed = append(listed, fn)
+		}
+	}
+	return listed
+}
+
+// DeprecatedSymbols lists the deprecated symbols shown in the deprecated API
+// index
+func (e *Engine) DeprecatedSymbols(packages []*discovery.PackageInfo) []*discovery.DeprecatedSymbol {
+	var listed []*discovery.DeprecatedSymbol
+	for _, symbol := range discovery.DeprecatedSymbols(packages) {
+		if e.listed(symbol.Exported) {
+			listed = append(listed, symbol)
+		}
 	}
+	return listed
 }
 
 // RenderToFile renders a template to a file
@@ -0,0 +1,216 @@
+package templates
+
+import (
+	"go/scanner"
+	"go/token"
+	"html"
+	"path"
+	"strings"
+
+	"github.com/kolosys/proton/internal/discovery"
+)
+
+// SetSymbolIndex sets the index used to resolve cross-references. It must be
+// called before rendering pages that link types.
+func (e *Engine) SetSymbolIndex(index *discovery.SymbolIndex) {
+	e.index = index
+}
+
+// linkToken is a token of Go source being linked
+type linkToken struct {
+	tok    token.Token
+	lit    string
+	offset int
+}
+
+// linkCode escapes Go source for HTML and turns every identifier referring to
+// a documented symbol into a link. Unqualified identifiers are resolved in
+// pkg, qualified ones through the imports of pkg. Links are relative to the
+// api-reference directory.
+func (e *Engine) linkCode(src string, pkg *discovery.PackageInfo) string {
+	fileSet := token.NewFileSet()
+	file := fileSet.AddFile("", -1, len(src))
+
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	var tokens []linkToken
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.COMMENT {
+			continue
+		}
+		if lit == "" {
+			lit = tok.String()
+		}
+		tokens = append(tokens, linkToken{tok: tok, lit: lit, offset: file.Offset(pos)})
+	}
+
+	valueNames := groupValueNames(tokens)
+
+	var out strings.Builder
+	last := 0
+	emit := func(start, end int, url string) {
+		out.WriteString(html.EscapeString(src[last:start]))
+		out.WriteString(`<a href="` + html.EscapeString(url) + `">`)
+		out.WriteString(html.EscapeString(src[start:end]))
+		out.WriteString(`</a>`)
+		last = end
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		current := tokens[i]
+		if current.tok != token.IDENT || (i > 0 && tokens[i-1].tok == token.PERIOD) {
+			continue
+		}
+
+		// Qualified identifier, e.g. config.Config
+		if i+2 < len(tokens) && tokens[i+1].tok == token.PERIOD && tokens[i+2].tok == token.IDENT {
+			name := tokens[i+2]
+			if pkg != nil {
+				if importPath, ok := pkg.Imports[current.lit]; ok {
+					if url := e.symbolURL(importPath, name.lit); url != "" {
+						emit(current.offset, name.offset+len(name.lit), url)
+					}
+				}
+			}
+			i += 2
+			continue
+		}
+
+		if pkg == nil || valueNames[i] || isDeclaredName(tokens, i) {
+			continue
+		}
+		if url := e.symbolURL(pkg.ImportPath, current.lit); url != "" {
+			emit(current.offset, current.offset+len(current.lit), url)
+		}
+	}
+	out.WriteString(html.EscapeString(src[last:]))
+
+	return out.String()
+}
+
+// groupValueNames marks the names declared by the specs of grouped const and
+// var declarations, e.g. "B" in "const (\n\tA T = iota\n\tB\n)"
+func groupValueNames(tokens []linkToken) map[int]bool {
+	names := make(map[int]bool)
+
+	groupDepth, depth := -1, 0
+	inNames := false
+	for i, current := range tokens {
+		switch current.tok {
+		case token.LPAREN, token.LBRACE, token.LBRACK:
+			depth++
+			if current.tok == token.LPAREN && i > 0 && (tokens[i-1].tok == token.CONST || tokens[i-1].tok == token.VAR) {
+				groupDepth = depth
+				inNames = true
+				continue
+			}
+		case token.RPAREN, token.RBRACE, token.RBRACK:
+			if depth == groupDepth {
+				groupDepth = -1
+			}
+			depth--
+		case token.SEMICOLON:
+			if depth == groupDepth {
+				inNames = true
+				continue
+			}
+		case token.IDENT:
+			// A name list alternates between names and commas
+			if inNames && depth == groupDepth && (i == 0 || tokens[i-1].tok != token.IDENT) {
+				names[i] = true
+				continue
+			}
+		case token.COMMA:
+			if inNames && depth == groupDepth && i > 0 && tokens[i-1].tok == token.IDENT {
+				continue
+			}
+		}
+		inNames = false
+	}
+
+	return names
+}
+
+// isDeclaredName reports whether the identifier at i names something being
+// declared, e.g. a field, parameter, function or type name, rather than
+// referring to a type
+func isDeclaredName(tokens []linkToken, i int) bool {
+	if i > 0 {
+		switch tokens[i-1].tok {
+		case token.TYPE, token.FUNC, token.CONST, token.VAR:
+			return true
+		}
+	}
+	if i+1 >= len(tokens) {
+		return false
+	}
+
+	next := tokens[i+1]
+	switch next.tok {
+	case token.IDENT, token.MUL, token.FUNC, token.MAP, token.CHAN, token.STRUCT,
+		token.INTERFACE, token.ELLIPSIS, token.ARROW, token.LPAREN:
+		return true
+	case token.ASSIGN:
+		// "A, B = 1, 2" declares B, "A T = 1" refers to T
+		return i == 0 || tokens[i-1].tok == token.COMMA
+	case token.LBRACK:
+		// "Name []T" declares a field, "List[T]" instantiates a generic type
+		return next.offset > tokens[i].offset+len(tokens[i].lit)
+	}
+	return false
+}
+
+// symbolURL returns the URL of a symbol, or "" if it can't be linked.
+// Symbols of discovered packages link to their API reference page, all
+// others to the configured external documentation.
+func (e *Engine) symbolURL(importPath, name string) string {
+	if symbol := e.index.Lookup(importPath, name); symbol != nil {
+		return path.Base(symbol.Page) + "#" + symbol.Anchor
+	}
+
+	// Unexported and undocumented symbols of discovered packages have no page
+	if e.index.Package(importPath) != nil || importPath == "" || !token.IsExported(name) {
+		return ""
+	}
+
+	url := e.config.Links.External
+	if discovery.IsStandardLibrary(importPath) {
+		url = e.config.Links.Stdlib
+	}
+	if url == "" {
+		return ""
+	}
+
+	return strings.NewReplacer("{import_path}", importPath, "{symbol}", name).Replace(url)
+}
+
+// typeLink renders a type expression as inline code with links to the
+// documentation of the types it refers to
+func (e *Engine) typeLink(typeName string, pkg ...*discovery.PackageInfo) string {
+	var context *discovery.PackageInfo
+	if len(pkg) > 0 {
+		context = pkg[0]
+	}
+	return "<code>" + strings.ReplaceAll(e.linkCode(typeName, context), "|", "&#124;") + "</code>"
+}
+
+// symbolLink renders text as inline code linking to the documentation of a
+// symbol, e.g. a promoted method to its declaration on the embedded type
+func (e *Engine) symbolLink(importPath, name, text string) string {
+	text = strings.ReplaceAll(html.EscapeString(text), "|", "&#124;")
+	if url := e.symbolURL(importPath, name); url != "" {
+		return `<code><a href="` + html.EscapeString(url) + `">` + text + `</a></code>`
+	}
+	return "<code>" + text + "</code>"
+}
+
+// linkDecl renders a declaration as a Go code block with links to the
+// documentation of the types it refers to
+func (e *Engine) linkDecl(decl string, pkg *discovery.PackageInfo) string {
+	return `<pre><code class="language-go">` + e.linkCode(decl, pkg) + "</code></pre>"
+}
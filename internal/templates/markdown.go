@@ -0,0 +1,141 @@
+package templates
+
+import (
+	"go/doc/comment"
+	"path"
+	"strings"
+
+	"github.com/kolosys/proton/internal/discovery"
+)
+
+// markdown renders a doc comment as Markdown. Code blocks become fenced Go
+// blocks and doc links such as [Client] or [io.Reader] link to the generated
+// pages of discovered packages, or to the configured external documentation.
+// Links into discovered packages are only produced when the package the
+// comment belongs to is given, since they are relative to the api-reference
+// directory. The "Deprecated:" paragraph is left out, templates render it
+// from the symbol's Deprecation.
+func (e *Engine) markdown(text string, pkg ...*discovery.PackageInfo) string {
+	var context *discovery.PackageInfo
+	if len(pkg) > 0 {
+		context = pkg[0]
+	}
+
+	doc := parseDoc(e.commentParser(context), text)
+	printer := e.commentPrinter(context)
+
+	var out strings.Builder
+	for i, block := range doc.Content {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		if code, ok := block.(*comment.Code); ok {
+			out.WriteString("```go\n" + code.Text + "```\n")
+			continue
+		}
+		out.Write(printer.Markdown(&comment.Doc{Content: []comment.Block{block}, Links: doc.Links}))
+	}
+
+	return strings.TrimSpace(out.String())
+}
+
+// inlineMarkdown renders a doc comment as a single line of Markdown, for use
+// in table cells
+func (e *Engine) inlineMarkdown(text string, pkg ...*discovery.PackageInfo) string {
+	var context *discovery.PackageInfo
+	if len(pkg) > 0 {
+		context = pkg[0]
+	}
+
+	doc := parseDoc(e.commentParser(context), text)
+	line := strings.Join(strings.Fields(string(e.commentPrinter(context).Markdown(doc))), " ")
+	return strings.ReplaceAll(line, "|", "\\|")
+}
+
+// parseDoc parses a doc comment without its "Deprecated:" paragraph
+func parseDoc(parser *comment.Parser, text string) *comment.Doc {
+	doc := parser.Parse(text)
+
+	content := doc.Content[:0]
+	for _, block := range doc.Content {
+		if paragraph, ok := block.(*comment.Paragraph); ok && len(paragraph.Text) > 0 {
+			if plain, ok := paragraph.Text[0].(comment.Plain); ok && strings.HasPrefix(string(plain), "Deprecated:") {
+				continue
+			}
+		}
+		content = append(content, block)
+	}
+	doc.Content = content
+
+	return doc
+}
+
+// commentParser returns a doc comment parser resolving package names through
+// the imports of pkg and symbols through the symbol index
+func (e *Engine) commentParser(pkg *discovery.PackageInfo) *comment.Parser {
+	parser := &comment.Parser{}
+	if pkg == nil {
+		return parser
+	}
+
+	parser.LookupPackage = func(name string) (string, bool) {
+		if importPath, ok := pkg.Imports[name]; ok {
+			return importPath, true
+		}
+		if name == pkg.Name {
+			return pkg.ImportPath, true
+		}
+		return "", false
+	}
+	parser.LookupSym = func(recv, name string) bool {
+		if recv != "" {
+			name = recv + "." + name
+		}
+		return e.index.Lookup(pkg.ImportPath, name) != nil
+	}
+
+	return parser
+}
+
+// commentPrinter returns a Markdown printer for doc comments of pkg. Headings
+// are nested below the symbol headings of the API reference.
+func (e *Engine) commentPrinter(pkg *discovery.PackageInfo) *comment.Printer {
+	return &comment.Printer{
+		HeadingLevel: 4,
+		HeadingID:    func(*comment.Heading) string { return "" },
+		DocLinkURL: func(link *comment.DocLink) string {
+			return e.docLinkURL(link, pkg)
+		},
+	}
+}
+
+// docLinkURL returns the URL of a doc link, or "" to render it as plain text
+func (e *Engine) docLinkURL(link *comment.DocLink, pkg *discovery.PackageInfo) string {
+	importPath := link.ImportPath
+	if importPath == "" && pkg != nil {
+		importPath = pkg.ImportPath
+	}
+
+	// Pages of discovered packages can only be linked relative to a package
+	if pkg == nil && e.index.Package(importPath) != nil {
+		return ""
+	}
+
+	if link.Name == "" {
+		if target := e.index.Package(importPath); target != nil {
+			return path.Base(discovery.APIReferencePage(target))
+		}
+		url := e.config.Links.External
+		if discovery.IsStandardLibrary(importPath) {
+			url = e.config.Links.Stdlib
+		}
+		url = strings.NewReplacer("{import_path}", importPath, "{symbol}", "").Replace(url)
+		return strings.TrimSuffix(url, "#")
+	}
+
+	name := link.Name
+	if link.Recv != "" {
+		name = link.Recv + "." + name
+	}
+	return e.symbolURL(importPath, name)
+}
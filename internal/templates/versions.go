@@ -0,0 +1,92 @@
+package templates
+
+import (
+	"html"
+	"path"
+	"strings"
+
+	"github.com/kolosys/proton/internal/discovery"
+)
+
+// Version is a documented version of the project, a tag or a branch
+type Version struct {
+	Name    string                 `json:"name"`
+	Dir     string                 `json:"dir"` // Directory of its pages, relative to the versions directory
+	Commit  string                 `json:"commit"`
+	Release bool                   `json:"release"` // A release tag of the form vX.Y.Z
+	Branch  bool                   `json:"branch"`  // The configured branch rather than a tag
+	Latest  bool                   `json:"latest"`  // The most recent release, also published as "latest"
+	Index   *discovery.SymbolIndex `json:"-"`       // Symbols documented for the version
+}
+
+// ShortCommit returns the abbreviated commit hash of the version
+func (v *Version) ShortCommit() string {
+	if len(v.Commit) > 12 {
+		return v.Commit[:12]
+	}
+	return v.Commit
+}
+
+// VersionsContext provides the documented versions for template rendering
+type VersionsContext struct {
+	*Context
+	Latest *Version `json:"latest"` // Version published as "latest", nil if nothing is documented
+	Home   string   `json:"home"`   // Index of the work tree's documentation, relative to the versions index
+}
+
+// SetVersions sets the versions API reference pages link between. current is
+// the version being rendered, nil for the work tree, and root is the path of
+// the versions directory relative to the api-reference directory.
+func (e *Engine) SetVersions(versions []*Version, current *Version, root string) {
+	e.versions = versions
+	e.version = current
+	e.versionsRoot = root
+}
+
+// packageVersions lists the versions documenting a package, linking to its
+// page in each version but the current one, followed by a link to the
+// versions index. It returns "" when no versions are documented.
+func (e *Engine) packageVersions(importPath string) string {
+	if len(e.versions) == 0 {
+		return ""
+	}
+
+	var links []string
+	for _, version := range e.versions {
+		pkg := version.Index.Package(importPath)
+		switch {
+		case pkg == nil:
+			continue
+		case version == e.version:
+			links = append(links, "**"+version.Name+"**")
+		default:
+			links = append(links, e.versionLink(version, discovery.APIReferencePage(pkg), ""))
+		}
+	}
+	links = append(links, "[all versions]("+e.versionsRoot+"README.md)")
+	return strings.Join(links, " · ")
+}
+
+// symbolVersions links to the documentation of a symbol in the other versions
+// declaring it, or returns "" if none does
+func (e *Engine) symbolVersions(importPath, name string) string {
+	var links []string
+	for _, version := range e.versions {
+		if version == e.version {
+			continue
+		}
+		if symbol := version.Index.Lookup(importPath, name); symbol != nil {
+			links = append(links, e.versionLink(version, symbol.Page, symbol.Anchor))
+		}
+	}
+	return strings.Join(links, " · ")
+}
+
+// versionLink renders a Markdown link to a page of a version
+func (e *Engine) versionLink(version *Version, page, anchor string) string {
+	url := e.versionsRoot + path.Join(version.Dir, page)
+	if anchor != "" {
+		url += "#" + anchor
+	}
+	return "[" + html.EscapeString(version.Name) + "](" + url + ")"
+}
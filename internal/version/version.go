@@ -0,0 +1,6 @@
+// Package version holds the Proton release version.
+package version
+
+// Version is the current Proton version. It is part of every discovery cache
+// key, so upgrading Proton invalidates previously cached results.
+var Version = "1.0.0"